@@ -0,0 +1,95 @@
+package radar
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// radarItemMetadataRegexp matches the HTML comment at the end of a checklist line which
+// records metadata that isn't otherwise shown, e.g. "<!-- added=2026-10-18T09:00:00Z -->".
+var radarItemMetadataRegexp = regexp.MustCompile(`\s*<!--\s*(.*?)\s*-->\s*$`)
+
+// radarItemAgeRegexp matches the age rendered by radarItemAge, e.g. "_(added 23 days ago)_".
+var radarItemAgeRegexp = regexp.MustCompile(`\s*_\(added \d+ days? ago\)_$`)
+
+// radarItemAge describes how long ago the item was added, e.g. "added 23 days ago".
+// It's blank for items added in the last day and items with no add date.
+func radarItemAge(addedAt, now time.Time) string {
+	if addedAt.IsZero() {
+		return ""
+	}
+	days := int(now.Sub(addedAt).Hours() / 24)
+	switch {
+	case days < 1:
+		return ""
+	case days == 1:
+		return "added 1 day ago"
+	default:
+		return fmt.Sprintf("added %d days ago", days)
+	}
+}
+
+// formatRadarItemMetadata renders the item's hidden metadata (when, how and by whom it was
+// added) as an HTML comment, or "" if there's none. Values are query-escaped.
+func formatRadarItemMetadata(item *RadarItem) string {
+	var fields []string
+	if !item.AddedAt.IsZero() {
+		fields = append(fields, "added="+item.AddedAt.UTC().Format(time.RFC3339))
+	}
+	if item.Source != "" {
+		fields = append(fields, "source="+url.QueryEscape(item.Source))
+	}
+	if item.SubmittedBy != "" {
+		fields = append(fields, "by="+url.QueryEscape(item.SubmittedBy))
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return "<!-- " + strings.Join(fields, " ") + " -->"
+}
+
+// parseRadarItemMetadata removes the metadata comment and rendered age from the end of the
+// text following a todo's link, setting the metadata on item. It returns the rest of the text.
+func parseRadarItemMetadata(text string, item *RadarItem) string {
+	if matches := radarItemMetadataRegexp.FindStringSubmatch(text); matches != nil {
+		text = text[:len(text)-len(matches[0])]
+		for _, field := range strings.Fields(matches[1]) {
+			key, value, _ := strings.Cut(field, "=")
+			value, err := url.QueryUnescape(value)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "added":
+				if addedAt, err := time.Parse(time.RFC3339, value); err == nil {
+					item.AddedAt = addedAt
+				}
+			case "source":
+				item.Source = value
+			case "by":
+				item.SubmittedBy = value
+			}
+		}
+	}
+	return radarItemAgeRegexp.ReplaceAllString(text, "")
+}
+
+// expireRadarItems splits items into those to keep and those added more than expireAfter
+// before now. Nothing expires if expireAfter is zero, nor do items with no add date.
+func expireRadarItems(items []RadarItem, expireAfter time.Duration, now time.Time) ([]RadarItem, []RadarItem) {
+	if expireAfter <= 0 {
+		return items, nil
+	}
+	var kept, expired []RadarItem
+	for _, item := range items {
+		if !item.AddedAt.IsZero() && now.Sub(item.AddedAt) > expireAfter {
+			expired = append(expired, item)
+		} else {
+			kept = append(kept, item)
+		}
+	}
+	return kept, expired
+}
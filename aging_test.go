@@ -0,0 +1,68 @@
+package radar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_radarItemAge(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "", radarItemAge(time.Time{}, now))
+	assert.Equal(t, "", radarItemAge(now.Add(-23*time.Hour), now))
+	assert.Equal(t, "added 1 day ago", radarItemAge(now.Add(-25*time.Hour), now))
+	assert.Equal(t, "added 23 days ago", radarItemAge(now.AddDate(0, 0, -23), now))
+}
+
+func Test_expireRadarItems(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	items := []RadarItem{
+		{URL: "https://jvns.ca", AddedAt: now.AddDate(0, 0, -31)},
+		{URL: "https://byparker.com", AddedAt: now.AddDate(0, 0, -2)},
+		{URL: "https://github.com"},
+	}
+
+	kept, expired := expireRadarItems(items, 30*24*time.Hour, now)
+	assert.Equal(t, items[1:], kept)
+	assert.Equal(t, items[:1], expired)
+
+	kept, expired = expireRadarItems(items, 0, now)
+	assert.Equal(t, items, kept)
+	assert.Empty(t, expired)
+}
+
+func Test_parseLinkedTodo_metadata(t *testing.T) {
+	addedAt := time.Now().AddDate(0, 0, -23).UTC().Truncate(time.Second)
+	item := RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", Tags: []string{"zines"}, Note: "So good", AddedAt: addedAt}
+
+	markdown := item.GetTodoMarkdown()
+	assert.Equal(t, "[Julia Evans](https://jvns.ca) — #zines So good _(added 23 days ago)_ <!-- added="+addedAt.Format(time.RFC3339)+" -->", markdown)
+	assert.Equal(t, item, parseLinkedTodo(markdown))
+
+	item = RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", AddedAt: time.Now().UTC().Truncate(time.Second)}
+	assert.Equal(t, item, parseLinkedTodo(item.GetTodoMarkdown()))
+
+	item = RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", Source: SourceEmail, SubmittedBy: "Parker Moore <parker@example.com>"}
+	markdown = item.GetTodoMarkdown()
+	assert.Equal(t, "[Julia Evans](https://jvns.ca) <!-- source=email by=Parker+Moore+%3Cparker%40example.com%3E -->", markdown)
+	assert.Equal(t, item, parseLinkedTodo(markdown))
+}
+
+func Test_generateBody_expired(t *testing.T) {
+	data := &tmplData{
+		Mention:  "@parkr",
+		NewLinks: []RadarItem{{URL: "https://byparker.com", Title: "By Parker", AddedAt: time.Now()}},
+		OldLinks: []RadarItem{{URL: "https://jvns.ca", Title: "Julia Evans", AddedAt: time.Now().AddDate(0, 0, -40)}},
+	}
+	data.expire(30*24*time.Hour, time.Now())
+	assert.Empty(t, data.OldLinks)
+
+	body, err := generateBody(data)
+	assert.NoError(t, err)
+	assert.Contains(t, body, "## Expired:\n\n  * [Julia Evans](https://jvns.ca) — _(added 40 days ago)_")
+
+	items, err := extractLinkedTodosFromMarkdown(body)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"By Parker"}, titlesOf(items))
+}
@@ -1,8 +1,19 @@
 package radar
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/technoweenie/grohl"
 )
@@ -10,11 +21,20 @@ import (
 type apiListItemsResponse struct {
 	OldRadarItems []RadarItem `json:"OldItems"`
 	NewRadarItems []RadarItem `json:"NewRadarItems"`
+	// NextCursor can be passed as cursor= to fetch the next page. It's blank on the last page.
+	NextCursor string `json:"NextCursor,omitempty"`
 }
 
-func NewAPIHandler(radarItemsService RadarItemsService, debug bool, radarGeneratedChan chan bool) APIHandler {
+type apiListDoneItemsResponse struct {
+	DoneRadarItems []RadarItem `json:"DoneItems"`
+	// NextCursor can be passed as cursor= to fetch the next page. It's blank on the last page.
+	NextCursor string `json:"NextCursor,omitempty"`
+}
+
+func NewAPIHandler(radarItemsService RadarItemsStorageService, tokens APITokens, debug bool, radarGeneratedChan chan bool) APIHandler {
 	return APIHandler{
 		RadarItems:         radarItemsService,
+		Tokens:             tokens,
 		Debug:              debug,
 		radarGeneratedChan: radarGeneratedChan,
 	}
@@ -24,7 +44,10 @@ var apiPrefix = "/api/radar_items"
 
 type APIHandler struct {
 	// RadarItem service
-	RadarItems RadarItemsService
+	RadarItems RadarItemsStorageService
+
+	// Bearer tokens allowed to use the API. If empty, the API is unauthenticated.
+	Tokens APITokens
 
 	// Enable debug logging.
 	Debug bool
@@ -33,12 +56,180 @@ type APIHandler struct {
 	radarGeneratedChan chan bool
 }
 
+// apiError is a machine-readable error returned by the API.
+type apiError struct {
+	// Code is a stable identifier for the kind of error, e.g. "not_found".
+	Code string `json:"Code"`
+	// Message is a human-readable description of the error.
+	Message string `json:"Message"`
+}
+
+type apiErrorResponse struct {
+	Error apiError `json:"Error"`
+	// Items that were created before the error occurred, if any.
+	Items []RadarItem `json:"Items,omitempty"`
+	// Existing is the item already on the radar, for "duplicate" errors.
+	Existing *RadarItem `json:"Existing,omitempty"`
+}
+
+type apiCreateItemsResponse struct {
+	Items []RadarItem `json:"Items"`
+}
+
+// apiItemRequest is the body used to create or update a single radar item. Its keys are
+// matched case-insensitively, so "url" works as well as "URL".
+type apiItemRequest struct {
+	URL   string   `json:"URL"`
+	Title string   `json:"Title"`
+	Tags  []string `json:"Tags"`
+	Note  string   `json:"Note"`
+	// Source is SourcePoster for items from radar-poster. Anything else is recorded as SourceAPI.
+	Source string `json:"Source"`
+}
+
+func (req apiItemRequest) radarItem() RadarItem {
+	source := SourceAPI
+	if req.Source == SourcePoster {
+		source = SourcePoster
+	}
+	return RadarItem{
+		URL:    req.URL,
+		Title:  req.Title,
+		Tags:   normalizeTags(req.Tags),
+		Note:   strings.TrimSpace(req.Note),
+		Source: source,
+	}
+}
+
+var apiErrorCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusConflict:              "duplicate",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusInternalServerError:   "internal_error",
+	http.StatusRequestEntityTooLarge: "request_too_large",
+}
+
 func (h APIHandler) Error(w http.ResponseWriter, message string, code int) {
+	h.errorWithItems(w, message, code, nil)
+}
+
+func (h APIHandler) errorWithItems(w http.ResponseWriter, message string, code int, items []RadarItem) {
+	h.writeError(w, code, apiErrorResponse{Error: apiError{Message: message}, Items: items})
+}
+
+// writeError fills in the error code from the status code and writes the response.
+func (h APIHandler) writeError(w http.ResponseWriter, code int, response apiErrorResponse) {
+	message := response.Error.Message
 	grohl.Log(grohl.Data{"status": code, "message": message})
-	http.Error(w, message, code)
+	errorCode, ok := apiErrorCodes[code]
+	if !ok {
+		errorCode = strings.ReplaceAll(strings.ToLower(http.StatusText(code)), " ", "_")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	response.Error.Code = errorCode
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// validateItemURL returns an error unless rawURL is an absolute http or https URL without
+// whitespace, which is all a digest can store and read back.
+func validateItemURL(rawURL string) error {
+	if strings.IndexFunc(rawURL, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("url cannot contain whitespace: %q", rawURL)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL: %q", rawURL)
+	}
+	return nil
+}
+
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/json"
+}
+
+// parseItemRequests reads one or more items from a JSON body (an object or an array of
+// objects), or a single item from form values.
+func parseItemRequests(r *http.Request) ([]apiItemRequest, error) {
+	if !isJSONRequest(r) {
+		return []apiItemRequest{{
+			URL:    r.FormValue("url"),
+			Title:  r.FormValue("title"),
+			Note:   r.FormValue("note"),
+			Tags:   r.Form["tags"],
+			Source: r.FormValue("source"),
+		}}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAPIRequestBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.TrimSpace(body)
+	if bytes.HasPrefix(body, []byte("[")) {
+		var reqs []apiItemRequest
+		if err := json.Unmarshal(body, &reqs); err != nil {
+			return nil, err
+		}
+		return reqs, nil
+	}
+	var req apiItemRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	return []apiItemRequest{req}, nil
+}
+
+// maxAPIRequestBodyBytes limits the size of JSON bodies accepted by the API.
+const maxAPIRequestBodyBytes = 1 << 20
+
+type apiTokenNameKeyValue struct{}
+
+var apiTokenNameKey = &apiTokenNameKeyValue{}
+
+// apiTokenName returns the name of the token the request was authenticated with, if any.
+func apiTokenName(r *http.Request) string {
+	name, _ := r.Context().Value(apiTokenNameKey).(string)
+	return name
+}
+
+// authenticate checks the request's bearer token and records the token's name in the log
+// context and in the returned request's context.
+func (h APIHandler) authenticate(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if len(h.Tokens) == 0 {
+		return r, true
+	}
+
+	token, ok := bearerToken(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="radar"`)
+		h.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return r, false
+	}
+
+	name, ok := h.Tokens.Authenticate(token)
+	if !ok {
+		h.Error(w, "invalid bearer token", http.StatusForbidden)
+		return r, false
+	}
+
+	if logCtx := getLogContextOrNil(r); logCtx != nil {
+		logCtx.Add("token_name", name)
+	}
+	return r.WithContext(context.WithValue(r.Context(), apiTokenNameKey, name)), true
 }
 
 func (h APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
 	if r.Method == http.MethodPost && r.URL.Path == apiPrefix {
 		h.CreateRadarItem(w, r)
 		h.radarGeneratedChan <- true
@@ -50,41 +241,199 @@ func (h APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// /api/radar_items/{id} and /api/radar_items/{id}/done
+	if rest := strings.TrimPrefix(r.URL.Path, apiPrefix+"/"); rest != r.URL.Path {
+		pieces := strings.Split(rest, "/")
+		id, err := strconv.ParseInt(pieces[0], 10, 64)
+		if err != nil {
+			h.Error(w, "invalid radar item id: "+pieces[0], http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case len(pieces) == 1 && r.Method == http.MethodGet:
+			h.GetRadarItem(w, r, id)
+			return
+		case len(pieces) == 1 && r.Method == http.MethodPatch:
+			h.UpdateRadarItem(w, r, id)
+			h.radarGeneratedChan <- true
+			return
+		case len(pieces) == 1 && r.Method == http.MethodDelete:
+			h.DeleteRadarItem(w, r, id)
+			h.radarGeneratedChan <- true
+			return
+		case len(pieces) == 2 && pieces[1] == "done" && r.Method == http.MethodPost:
+			h.CheckRadarItem(w, r, id)
+			h.radarGeneratedChan <- true
+			return
+		}
+	}
+
 	h.Error(w, "404 not found at all", http.StatusNotFound)
 }
 
-func (h APIHandler) CreateRadarItem(w http.ResponseWriter, r *http.Request) {
-	url := r.FormValue("url")
-	if url == "" {
-		h.Error(w, "url cannot be blank", http.StatusBadRequest)
+// storageError writes err with a status code appropriate to it.
+func (h APIHandler) storageError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrRadarItemNotFound) {
+		h.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	h.Error(w, err.Error(), http.StatusInternalServerError)
+}
 
-	err := h.RadarItems.Create(r.Context(), RadarItem{
-		URL:   url,
-		Title: r.FormValue("title"),
-	})
-	if err != nil {
+func (h APIHandler) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
 		h.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h APIHandler) GetRadarItem(w http.ResponseWriter, r *http.Request, id int64) {
+	item, err := h.RadarItems.Get(r.Context(), id)
+	if err != nil {
+		h.storageError(w, err)
+		return
+	}
+	h.writeJSON(w, item)
+}
+
+func (h APIHandler) UpdateRadarItem(w http.ResponseWriter, r *http.Request, id int64) {
+	reqs, err := parseItemRequests(r)
+	if err != nil {
+		h.Error(w, "couldn't parse request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(reqs) != 1 {
+		h.Error(w, "exactly one item must be given", http.StatusBadRequest)
 		return
 	}
+	update := reqs[0].radarItem()
+	if update.URL == "" && update.Title == "" && update.Tags == nil && update.Note == "" {
+		h.Error(w, "url, title, tags or note is required", http.StatusBadRequest)
+		return
+	}
+	if update.URL != "" {
+		if err := validateItemURL(update.URL); err != nil {
+			h.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	item, err := h.RadarItems.Update(r.Context(), id, update)
+	if err != nil {
+		h.storageError(w, err)
+		return
+	}
+	h.writeJSON(w, item)
+}
 
-	h.Error(w, "successfully saved url", http.StatusCreated)
+func (h APIHandler) CheckRadarItem(w http.ResponseWriter, r *http.Request, id int64) {
+	if err := h.RadarItems.Check(r.Context(), id); err != nil {
+		h.storageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h APIHandler) DeleteRadarItem(w http.ResponseWriter, r *http.Request, id int64) {
+	if err := h.RadarItems.Delete(r.Context(), id); err != nil {
+		h.storageError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h APIHandler) CreateRadarItem(w http.ResponseWriter, r *http.Request) {
+	reqs, err := parseItemRequests(r)
+	if err != nil {
+		h.Error(w, "couldn't parse request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(reqs) == 0 {
+		h.Error(w, "no items to create", http.StatusBadRequest)
+		return
+	}
+	for _, req := range reqs {
+		if req.URL == "" {
+			h.Error(w, "url cannot be blank", http.StatusBadRequest)
+			return
+		}
+		if err := validateItemURL(req.URL); err != nil {
+			h.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	created := make([]RadarItem, 0, len(reqs))
+	for _, req := range reqs {
+		m := req.radarItem()
+		m.SubmittedBy = apiTokenName(r)
+		item, err := h.RadarItems.Create(r.Context(), m)
+		var duplicateErr *DuplicateRadarItemError
+		if errors.As(err, &duplicateErr) {
+			h.writeError(w, http.StatusConflict, apiErrorResponse{
+				Error:    apiError{Message: err.Error()},
+				Items:    created,
+				Existing: &duplicateErr.Existing,
+			})
+			return
+		}
+		if err != nil {
+			h.errorWithItems(w, err.Error(), http.StatusInternalServerError, created)
+			return
+		}
+		created = append(created, *item)
+	}
+
+	grohl.Log(grohl.Data{"status": http.StatusCreated, "message": "successfully saved url", "count": len(created)})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(apiCreateItemsResponse{Items: created})
 }
 
 func (h APIHandler) ListRadarItems(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
+	if err != nil {
+		h.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if query.State == listStateDone {
+		h.listDoneRadarItems(w, r, query)
+		return
+	}
+
 	oldRadarItems, newRadarItems, err := h.RadarItems.List(r.Context())
 	if err != nil {
 		h.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	err = json.NewEncoder(w).Encode(apiListItemsResponse{
-		OldRadarItems: oldRadarItems,
-		NewRadarItems: newRadarItems,
-	})
+	response := apiListItemsResponse{}
+	response.OldRadarItems, response.NewRadarItems, response.NextCursor = query.apply(oldRadarItems, newRadarItems)
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		h.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 }
+
+// listDoneRadarItems responds with the items which have been checked off, most recently checked first.
+func (h APIHandler) listDoneRadarItems(w http.ResponseWriter, r *http.Request, query listQuery) {
+	since := query.Since
+	if since.IsZero() {
+		// Listing everything ever checked off means reading every retired digest, which
+		// costs the GitHub backend several API calls each, so default to the done feed's window.
+		since = time.Now().Add(-doneFeedWindow)
+	}
+	doneRadarItems, err := h.RadarItems.ListDone(r.Context(), since)
+	if err != nil {
+		h.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := apiListDoneItemsResponse{}
+	response.DoneRadarItems, _, response.NextCursor = query.apply(doneRadarItems, nil)
+	h.writeJSON(w, response)
+}
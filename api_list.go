@@ -0,0 +1,176 @@
+package radar
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultListLimit is the page size used when a cursor is given without a limit.
+const defaultListLimit = 50
+
+// radarItemSorts maps each supported value of the sort= parameter to a comparison of two items.
+var radarItemSorts = map[string]func(a, b *RadarItem) bool{
+	"host": func(a, b *RadarItem) bool {
+		return a.GetHostname() < b.GetHostname()
+	},
+	"title": func(a, b *RadarItem) bool {
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	},
+	"added": func(a, b *RadarItem) bool {
+		return a.AddedAt.Before(b.AddedAt)
+	},
+	"checked": func(a, b *RadarItem) bool {
+		return a.CheckedAt.Before(b.CheckedAt)
+	},
+}
+
+const (
+	// listStateOpen lists the unchecked items on the radar.
+	listStateOpen = "open"
+	// listStateDone lists the items which have been checked off.
+	listStateDone = "done"
+)
+
+// listQuery holds the filtering, sorting and pagination parameters for GET /api/radar_items.
+type listQuery struct {
+	// State is listStateOpen or listStateDone.
+	State string
+	// Host only includes items whose hostname matches, ignoring a leading "www.".
+	Host string
+	// Q only includes items whose title or URL contains it, case-insensitively.
+	Q string
+	// Since only includes items added at or after it, or for done items, checked off at or after it.
+	Since time.Time
+	// Sort is a key of radarItemSorts, optionally prefixed with "-" to reverse it.
+	Sort string
+	// Limit is the maximum number of items to return. Zero means no limit.
+	Limit int
+	// Offset is the number of matching items to skip, decoded from the cursor.
+	Offset int
+}
+
+func parseListQuery(r *http.Request) (listQuery, error) {
+	values := r.URL.Query()
+	query := listQuery{
+		State: values.Get("state"),
+		Host:  strings.TrimPrefix(strings.ToLower(values.Get("host")), "www."),
+		Q:     strings.ToLower(values.Get("q")),
+		Sort:  values.Get("sort"),
+	}
+
+	switch query.State {
+	case "":
+		query.State = listStateOpen
+	case listStateOpen, listStateDone:
+	default:
+		return query, fmt.Errorf("state must be open or done, got %q", query.State)
+	}
+
+	if since := values.Get("since"); since != "" {
+		var err error
+		if query.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			if query.Since, err = time.Parse("2006-01-02", since); err != nil {
+				return query, fmt.Errorf("since must be RFC 3339 or YYYY-MM-DD, got %q", since)
+			}
+		}
+	}
+
+	if query.Sort != "" {
+		if _, ok := radarItemSorts[strings.TrimPrefix(query.Sort, "-")]; !ok {
+			return query, fmt.Errorf("sort must be one of host, title, added or checked, got %q", query.Sort)
+		}
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		var err error
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit < 1 {
+			return query, fmt.Errorf("limit must be a positive integer, got %q", limit)
+		}
+	}
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		var err error
+		if query.Offset, err = strconv.Atoi(cursor); err != nil || query.Offset < 0 {
+			return query, fmt.Errorf("invalid cursor %q", cursor)
+		}
+		if query.Limit == 0 {
+			query.Limit = defaultListLimit
+		}
+	}
+
+	return query, nil
+}
+
+func (q listQuery) matches(item *RadarItem) bool {
+	if q.Host != "" && strings.TrimPrefix(strings.ToLower(item.GetHostname()), "www.") != q.Host {
+		return false
+	}
+	if q.Q != "" && !strings.Contains(strings.ToLower(item.Title), q.Q) && !strings.Contains(strings.ToLower(item.URL), q.Q) {
+		return false
+	}
+	at := item.AddedAt
+	if q.State == listStateDone {
+		at = item.CheckedAt
+	}
+	if !q.Since.IsZero() && at.Before(q.Since) {
+		return false
+	}
+	return true
+}
+
+// apply filters, sorts and paginates the old and new items together, keeping them separate
+// in the result. It returns the cursor for the next page, or "" if this is the last page.
+func (q listQuery) apply(oldItems, newItems []RadarItem) ([]RadarItem, []RadarItem, string) {
+	type listedItem struct {
+		RadarItem
+		isNew bool
+	}
+
+	var listed []listedItem
+	for _, item := range newItems {
+		if q.matches(&item) {
+			listed = append(listed, listedItem{item, true})
+		}
+	}
+	for _, item := range oldItems {
+		if q.matches(&item) {
+			listed = append(listed, listedItem{item, false})
+		}
+	}
+
+	if q.Sort != "" {
+		less := radarItemSorts[strings.TrimPrefix(q.Sort, "-")]
+		descending := strings.HasPrefix(q.Sort, "-")
+		sort.SliceStable(listed, func(i, j int) bool {
+			if descending {
+				return less(&listed[j].RadarItem, &listed[i].RadarItem)
+			}
+			return less(&listed[i].RadarItem, &listed[j].RadarItem)
+		})
+	}
+
+	nextCursor := ""
+	if q.Offset >= len(listed) {
+		listed = nil
+	} else {
+		listed = listed[q.Offset:]
+	}
+	if q.Limit > 0 && len(listed) > q.Limit {
+		listed = listed[:q.Limit]
+		nextCursor = strconv.Itoa(q.Offset + q.Limit)
+	}
+
+	filteredOld, filteredNew := []RadarItem{}, []RadarItem{}
+	for _, item := range listed {
+		if item.isNew {
+			filteredNew = append(filteredNew, item.RadarItem)
+		} else {
+			filteredOld = append(filteredOld, item.RadarItem)
+		}
+	}
+	return filteredOld, filteredNew, nextCursor
+}
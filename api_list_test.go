@@ -0,0 +1,124 @@
+package radar
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listWithQuery(t *testing.T, handler APIHandler, rawQuery string) (int, apiListItemsResponse) {
+	req := httptest.NewRequest(http.MethodGet, apiPrefix+"?"+rawQuery, nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	response := apiListItemsResponse{}
+	if rr.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	}
+	return rr.Code, response
+}
+
+func titlesOf(items []RadarItem) []string {
+	titles := []string{}
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	return titles
+}
+
+func TestApiHandler_ListItems_Query(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, time.October, d, 12, 0, 0, 0, time.UTC) }
+	storage := &fakeRadarItemsStorageService{
+		oldItems: []RadarItem{
+			{URL: "https://www.jvns.ca/blog", Title: "Julia Evans", AddedAt: day(1)},
+			{URL: "https://github.com/parkr/radar", Title: "parkr/radar", AddedAt: day(3)},
+		},
+		newItems: []RadarItem{
+			{URL: "https://byparker.com", Title: "By Parker", AddedAt: day(17)},
+			{URL: "https://jvns.ca/zines", Title: "Wizard Zines", AddedAt: day(18)},
+		},
+	}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+
+	code, response := listWithQuery(t, handler, "host=jvns.ca")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Julia Evans"}, titlesOf(response.OldRadarItems))
+	assert.Equal(t, []string{"Wizard Zines"}, titlesOf(response.NewRadarItems))
+
+	_, response = listWithQuery(t, handler, "q=PARKR")
+	assert.Equal(t, []string{"parkr/radar"}, titlesOf(response.OldRadarItems))
+	assert.Empty(t, response.NewRadarItems)
+
+	_, response = listWithQuery(t, handler, "q=zines")
+	assert.Empty(t, response.OldRadarItems)
+	assert.Equal(t, []string{"Wizard Zines"}, titlesOf(response.NewRadarItems))
+
+	_, response = listWithQuery(t, handler, "since=2026-10-03")
+	assert.Equal(t, []string{"parkr/radar"}, titlesOf(response.OldRadarItems))
+	assert.Len(t, response.NewRadarItems, 2)
+
+	_, response = listWithQuery(t, handler, "sort=-added&limit=3")
+	assert.Equal(t, []string{"Wizard Zines", "By Parker"}, titlesOf(response.NewRadarItems))
+	assert.Equal(t, []string{"parkr/radar"}, titlesOf(response.OldRadarItems))
+	assert.Equal(t, "3", response.NextCursor)
+
+	_, response = listWithQuery(t, handler, "sort=-added&limit=3&cursor="+response.NextCursor)
+	assert.Empty(t, response.NewRadarItems)
+	assert.Equal(t, []string{"Julia Evans"}, titlesOf(response.OldRadarItems))
+	assert.Equal(t, "", response.NextCursor)
+
+	_, response = listWithQuery(t, handler, "sort=title")
+	assert.Equal(t, []string{"By Parker", "Wizard Zines"}, titlesOf(response.NewRadarItems))
+	assert.Equal(t, []string{"Julia Evans", "parkr/radar"}, titlesOf(response.OldRadarItems))
+
+	for _, invalid := range []string{"sort=color", "limit=0", "cursor=abc", "since=yesterday"} {
+		code, _ = listWithQuery(t, handler, invalid)
+		assert.Equal(t, http.StatusBadRequest, code, invalid)
+	}
+}
+
+func TestApiHandler_ListItems_Done(t *testing.T) {
+	daysAgo := func(d int) time.Time { return time.Now().Add(-time.Duration(d) * 24 * time.Hour).Truncate(time.Second) }
+	storage := &fakeRadarItemsStorageService{
+		newItems: []RadarItem{{URL: "https://byparker.com", Title: "By Parker", AddedAt: daysAgo(17)}},
+		doneItems: []RadarItem{
+			{URL: "https://jvns.ca", Title: "Julia Evans", AddedAt: daysAgo(17), CheckedAt: daysAgo(1)},
+			{URL: "https://wizardzines.com", Title: "Wizard Zines", AddedAt: daysAgo(16), CheckedAt: daysAgo(9)},
+			{URL: "https://ben.balter.com", Title: "Ben Balter", AddedAt: daysAgo(100), CheckedAt: daysAgo(90)},
+		},
+	}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+
+	list := func(rawQuery string) (int, apiListDoneItemsResponse) {
+		req := httptest.NewRequest(http.MethodGet, apiPrefix+"?"+rawQuery, nil)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		response := apiListDoneItemsResponse{}
+		if rr.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		}
+		return rr.Code, response
+	}
+
+	// Without since=, only items checked off in the last doneFeedWindow are listed.
+	code, response := list("state=done")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"Julia Evans", "Wizard Zines"}, titlesOf(response.DoneRadarItems))
+
+	// since= filters on when items were checked off, not when they were added.
+	_, response = list("state=done&since=" + daysAgo(5).Format("2006-01-02"))
+	assert.Equal(t, []string{"Julia Evans"}, titlesOf(response.DoneRadarItems))
+
+	_, response = list("state=done&since=" + daysAgo(365).Format("2006-01-02"))
+	assert.Equal(t, []string{"Julia Evans", "Wizard Zines", "Ben Balter"}, titlesOf(response.DoneRadarItems))
+
+	_, response = list("state=done&sort=checked")
+	assert.Equal(t, []string{"Wizard Zines", "Julia Evans"}, titlesOf(response.DoneRadarItems))
+
+	code, _ = list("state=closed")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
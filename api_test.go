@@ -1,7 +1,9 @@
 package radar
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -16,7 +18,7 @@ import (
 func TestApiHandler_UnsupportedMethod(t *testing.T) {
 	// Create a new APIHandler with a mock RadarItemsService
 	mockService := RadarItemsService{}
-	handler := NewAPIHandler(mockService, false, make(chan bool, 100))
+	handler := NewAPIHandler(mockService, nil, false, make(chan bool, 100))
 
 	// Create a new HTTP request with an unsupported method
 	req, err := http.NewRequest("PUT", apiPrefix, nil)
@@ -26,26 +28,19 @@ func TestApiHandler_UnsupportedMethod(t *testing.T) {
 	handler.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusNotFound, rr.Code, "Expected status code 404 Not Found")
-	expectedBody := "404 not found at all\n"
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	expectedBody := `{"Error":{"Code":"not_found","Message":"404 not found at all"}}` + "\n"
 	assert.Equal(t, expectedBody, rr.Body.String(), "Expected response body to match")
 }
 
 func TestApiHandler_ListItems(t *testing.T) {
 	// Create a new APIHandler with a mock RadarItemsService
 	mux := http.NewServeMux()
-	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
-		json.NewEncoder(w).Encode(&github.IssuesSearchResult{
-			Total:             github.Int(1),
-			IncompleteResults: github.Bool(false),
-			Issues: []*github.Issue{
-				{
-					Title:   github.String("Issue 123"),
-					HTMLURL: github.String("http://example.com/issue/123"),
-					Number:  github.Int(123),
-					Body:    github.String(testData.newStyleBody),
-				},
-			},
-		})
+	handleOpenRadarIssue(t, mux, "monalisa/diary", &github.Issue{
+		Title:   github.String("Issue 123"),
+		HTMLURL: github.String("http://example.com/issue/123"),
+		Number:  github.Int(123),
+		Body:    github.String(testData.newStyleBody),
 	})
 	mux.HandleFunc("/repos/monalisa/diary/issues/123/comments", func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode([]*github.IssueComment{
@@ -59,9 +54,9 @@ func TestApiHandler_ListItems(t *testing.T) {
 
 	ghClient := github.NewClient(nil)
 	ghClient.BaseURL = serverURL
-	radarItemsService := NewRadarItemsService(ghClient, "monalisa", "diary")
+	radarItemsService := NewRadarItemsService(ghClient, "monalisa", "diary", "")
 	debug := false
-	handler := NewAPIHandler(radarItemsService, debug, make(chan bool, 100))
+	handler := NewAPIHandler(radarItemsService, nil, debug, make(chan bool, 100))
 
 	// Create a new HTTP request for listing items
 	req, err := http.NewRequest("GET", apiPrefix, nil)
@@ -82,21 +77,18 @@ func TestApiHandler_ListItems(t *testing.T) {
 func TestApiHandler_CreateItem(t *testing.T) {
 	// Create a new APIHandler with a mock RadarItemsService
 	mux := http.NewServeMux()
-	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
-		json.NewEncoder(w).Encode(&github.IssuesSearchResult{
-			Total:             github.Int(1),
-			IncompleteResults: github.Bool(false),
-			Issues: []*github.Issue{
-				{
-					Title:   github.String("Issue 123"),
-					HTMLURL: github.String("http://example.com/issue/123"),
-					Number:  github.Int(123),
-					Body:    github.String(testData.newStyleBody),
-				},
-			},
-		})
+	handleOpenRadarIssue(t, mux, "monalisa/diary", &github.Issue{
+		Title:   github.String("Issue 123"),
+		HTMLURL: github.String("http://example.com/issue/123"),
+		Number:  github.Int(123),
+		Body:    github.String(testData.newStyleBody),
 	})
 	mux.HandleFunc("/repos/monalisa/diary/issues/123/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			// Existing items are listed to check for duplicates.
+			json.NewEncoder(w).Encode([]*github.IssueComment{})
+			return
+		}
 		assert.Equal(t, http.MethodPost, r.Method)
 		body, err := io.ReadAll(r.Body)
 		assert.NoError(t, err, "Failed to read request body")
@@ -104,7 +96,7 @@ func TestApiHandler_CreateItem(t *testing.T) {
 		inputIssueComment := &github.IssueComment{}
 		err = json.Unmarshal(body, inputIssueComment)
 		assert.NoError(t, err, "Failed to unmarshal request body into IssueComment")
-		assert.Equal(t, "- [ ] [Some Great Site](https://somegreat.site)", inputIssueComment.GetBody())
+		assert.Regexp(t, `^- \[ \] \[Some Great Site\]\(https://somegreat.site\) <!-- added=\S+ source=api -->$`, inputIssueComment.GetBody())
 
 		w.WriteHeader(http.StatusCreated) // Respond with 201 Created
 		_, err = w.Write(body)            // Echo back the request body
@@ -116,9 +108,9 @@ func TestApiHandler_CreateItem(t *testing.T) {
 
 	ghClient := github.NewClient(nil)
 	ghClient.BaseURL = serverURL
-	radarItemsService := NewRadarItemsService(ghClient, "monalisa", "diary")
+	radarItemsService := NewRadarItemsService(ghClient, "monalisa", "diary", "")
 	debug := false
-	handler := NewAPIHandler(radarItemsService, debug, make(chan bool, 100))
+	handler := NewAPIHandler(radarItemsService, nil, debug, make(chan bool, 100))
 
 	// Create a new HTTP request for listing items
 	form := url.Values{}
@@ -133,3 +125,219 @@ func TestApiHandler_CreateItem(t *testing.T) {
 
 	assert.Equal(t, http.StatusCreated, rr.Code, "Expected status code 201 Created")
 }
+
+func TestApiHandler_CreateItem_FakeStorage(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+
+	form := url.Values{}
+	form.Set("url", "https://somegreat.site")
+	form.Set("title", "Some Great Site")
+	req, err := http.NewRequest("POST", apiPrefix, strings.NewReader(form.Encode()))
+	assert.NoError(t, err, "Failed to create request")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code, "Expected status code 201 Created")
+	assert.Equal(t, []RadarItem{{URL: "https://somegreat.site", Title: "Some Great Site", Source: SourceAPI}}, storage.newItems)
+}
+
+func TestApiHandler_ItemLifecycle_FakeStorage(t *testing.T) {
+	id := radarItemIDForURL("https://jvns.ca")
+	storage := &fakeRadarItemsStorageService{
+		newItems: []RadarItem{{ID: id, URL: "https://jvns.ca", Title: "Julia Evans"}},
+	}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+	itemPath := fmt.Sprintf("%s/%d", apiPrefix, id)
+
+	req := httptest.NewRequest(http.MethodGet, itemPath, nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"ID":%d,"URL":"https://jvns.ca","Title":"Julia Evans"}`, id), rr.Body.String(),
+		"unset fields and zero times are left out")
+	item := RadarItem{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &item))
+	assert.Equal(t, "Julia Evans", item.Title)
+
+	form := url.Values{}
+	form.Set("title", "Wizard Zines")
+	req = httptest.NewRequest(http.MethodPatch, itemPath, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &item))
+	assert.Equal(t, "Wizard Zines", item.Title)
+	assert.Equal(t, "https://jvns.ca", item.URL)
+
+	req = httptest.NewRequest(http.MethodPost, itemPath+"/done", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Empty(t, storage.newItems)
+
+	req = httptest.NewRequest(http.MethodDelete, itemPath, nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, apiPrefix+"/nope", nil)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestApiHandler_CheckItem(t *testing.T) {
+	mux := http.NewServeMux()
+	handleOpenRadarIssue(t, mux, "monalisa/diary", &github.Issue{
+		Title:   github.String("Issue 123"),
+		HTMLURL: github.String("http://example.com/issue/123"),
+		Number:  github.Int(123),
+		Body:    github.String(testData.newStyleBody),
+	})
+	mux.HandleFunc("/repos/monalisa/diary/issues/123/comments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*github.IssueComment{
+			{ID: github.Int64(456), Body: github.String("- [ ] [Some Great Site](https://somegreat.site)")},
+		})
+	})
+	editedComment := &github.IssueComment{}
+	mux.HandleFunc("/repos/monalisa/diary/issues/comments/456", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(editedComment))
+		json.NewEncoder(w).Encode(editedComment)
+	})
+	server := httptest.NewServer(mux)
+	serverURL, _ := url.Parse(server.URL + "/")
+	defer server.Close()
+
+	ghClient := github.NewClient(nil)
+	ghClient.BaseURL = serverURL
+	handler := NewAPIHandler(NewRadarItemsService(ghClient, "monalisa", "diary", ""), nil, false, make(chan bool, 100))
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("%s/%d/done", apiPrefix, radarItemIDForURL("https://somegreat.site")), nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "- [x] [Some Great Site](https://somegreat.site)", editedComment.GetBody())
+}
+
+func TestApiHandler_CreateItems_JSON(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+
+	req := httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`{"url": "https://jvns.ca", "title": "Julia Evans", "tags": ["reading"], "note": "zines!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	response := apiCreateItemsResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	expected := RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", Tags: []string{"reading"}, Note: "zines!", Source: SourceAPI}
+	assert.Equal(t, []RadarItem{expected}, response.Items)
+
+	req = httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`[{"url": "https://byparker.com", "title": "By Parker"}, {"url": "https://ben.balter.com", "title": "Ben Balter"}]`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Len(t, response.Items, 2)
+	assert.Len(t, storage.newItems, 3)
+}
+
+func TestApiHandler_CreateItems_JSONErrors(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+
+	req := httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`[{"url": "https://jvns.ca"}, {"title": "No URL"}]`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	response := apiErrorResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, apiError{Code: "bad_request", Message: "url cannot be blank"}, response.Error)
+	assert.Empty(t, storage.newItems, "no items should be created if any are invalid")
+
+	req = httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`{"url":`))
+	req.Header.Set("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "bad_request", response.Error.Code)
+}
+
+func TestApiHandler_InvalidURLs(t *testing.T) {
+	id := radarItemIDForURL("https://jvns.ca")
+	storage := &fakeRadarItemsStorageService{
+		newItems: []RadarItem{{ID: id, URL: "https://jvns.ca", Title: "Julia Evans"}},
+	}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+
+	for _, invalidURL := range []string{"https://a.com/x y", "jvns.ca", "ftp://jvns.ca", "javascript:alert(1)", "https://", " https://jvns.ca"} {
+		body, _ := json.Marshal(map[string]string{"url": invalidURL, "title": "Foo (bar)"})
+		req := httptest.NewRequest(http.MethodPost, apiPrefix, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "URL: %q", invalidURL)
+		response := apiErrorResponse{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, "bad_request", response.Error.Code)
+
+		req = httptest.NewRequest(http.MethodPatch, fmt.Sprintf("%s/%d", apiPrefix, id), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "URL: %q", invalidURL)
+	}
+	assert.Equal(t, []RadarItem{{ID: id, URL: "https://jvns.ca", Title: "Julia Evans"}}, storage.newItems)
+}
+
+func TestApiHandler_CreateItem_Duplicate(t *testing.T) {
+	existing := RadarItem{URL: "https://jvns.ca/", Title: "Julia Evans"}
+	storage := &fakeRadarItemsStorageService{oldItems: []RadarItem{existing}}
+	handler := NewAPIHandler(storage, nil, false, make(chan bool, 100))
+
+	req := httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(`{"url": "https://www.jvns.ca?utm_source=newsletter"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	response := apiErrorResponse{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, "duplicate", response.Error.Code)
+	assert.Equal(t, &existing, response.Existing)
+	assert.Empty(t, storage.newItems)
+}
+
+func TestApiHandler_CreateItem_RecordsSubmitter(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{}
+	handler := NewAPIHandler(storage, APITokens{"laptop": "abc"}, false, make(chan bool, 100))
+
+	form := url.Values{"url": {"https://jvns.ca"}, "title": {"Julia Evans"}, "source": {SourcePoster}}
+	req := httptest.NewRequest(http.MethodPost, apiPrefix, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer abc")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	if assert.Len(t, storage.newItems, 1) {
+		assert.Equal(t, "laptop", storage.newItems[0].SubmittedBy)
+		assert.Equal(t, SourcePoster, storage.newItems[0].Source)
+	}
+}
@@ -0,0 +1,99 @@
+package radar
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APITokens maps the name of each token (e.g. "laptop" or "browser-extension") to its secret value.
+type APITokens map[string]string
+
+// ParseAPITokens parses a comma-separated list of name:token pairs, e.g. "laptop:abc,phone:def".
+func ParseAPITokens(input string) (APITokens, error) {
+	tokens := APITokens{}
+	for i, pair := range strings.Split(input, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		pieces := strings.SplitN(pair, ":", 2)
+		if len(pieces) != 2 || pieces[0] == "" || pieces[1] == "" {
+			// Don't echo the entry: without a colon, it's probably the token itself.
+			return nil, fmt.Errorf("invalid token at entry %d: expected name:token", i+1)
+		}
+		tokens[pieces[0]] = pieces[1]
+	}
+	return tokens, nil
+}
+
+// LoadAPITokensFile reads a JSON object mapping token names to token values. Every name and
+// value must be set.
+func LoadAPITokensFile(path string) (APITokens, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tokens := APITokens{}
+	if err := json.NewDecoder(f).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("couldn't decode tokens at %q: %w", path, err)
+	}
+	for name, token := range tokens {
+		// Don't echo the value: it's the secret.
+		if name == "" {
+			return nil, fmt.Errorf("invalid token in %q: a token has a blank name", path)
+		}
+		if token == "" {
+			return nil, fmt.Errorf("invalid token %q in %q: its value is blank", name, path)
+		}
+	}
+	return tokens, nil
+}
+
+// Merge returns a new set of tokens containing both t and other. Tokens in other win.
+// Tokens with a blank name or value are dropped, since they'd match a request without a token.
+func (t APITokens) Merge(other APITokens) APITokens {
+	merged := APITokens{}
+	for _, tokens := range []APITokens{t, other} {
+		for name, token := range tokens {
+			if name == "" || token == "" {
+				continue
+			}
+			merged[name] = token
+		}
+	}
+	return merged
+}
+
+// Authenticate returns the name of the token matching the input.
+// Every token is compared in constant time so timing doesn't reveal which one nearly matched.
+// An empty input never matches, even if a token is blank.
+func (t APITokens) Authenticate(input string) (string, bool) {
+	if input == "" {
+		return "", false
+	}
+	matchedName := ""
+	for name, token := range t {
+		if token == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(input)) == 1 {
+			matchedName = name
+		}
+	}
+	return matchedName, matchedName != ""
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
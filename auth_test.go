@@ -0,0 +1,91 @@
+package radar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAPITokens(t *testing.T) {
+	tokens, err := ParseAPITokens("laptop:abc, extension:def,")
+	assert.NoError(t, err)
+	assert.Equal(t, APITokens{"laptop": "abc", "extension": "def"}, tokens)
+
+	tokens, err = ParseAPITokens("")
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	_, err = ParseAPITokens("laptop:abc,s3cr3t")
+	if assert.Error(t, err) {
+		assert.Equal(t, "invalid token at entry 2: expected name:token", err.Error())
+		assert.NotContains(t, err.Error(), "s3cr3t")
+	}
+
+	_, err = ParseAPITokens(":s3cr3t")
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "s3cr3t")
+	}
+}
+
+func TestLoadAPITokensFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"poster": "xyz"}`), 0600))
+
+	tokens, err := LoadAPITokensFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, APITokens{"poster": "xyz"}, tokens)
+	assert.Equal(t, APITokens{"poster": "xyz", "laptop": "abc"}, tokens.Merge(APITokens{"laptop": "abc"}))
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"poster": "xyz", "laptop": ""}`), 0600))
+	_, err = LoadAPITokensFile(path)
+	assert.ErrorContains(t, err, `invalid token "laptop"`)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"": "s3cr3t"}`), 0600))
+	_, err = LoadAPITokensFile(path)
+	if assert.ErrorContains(t, err, "blank name") {
+		assert.NotContains(t, err.Error(), "s3cr3t")
+	}
+}
+
+func TestAPITokens_Authenticate(t *testing.T) {
+	tokens := APITokens{"laptop": "abc", "extension": "def"}
+
+	name, ok := tokens.Authenticate("def")
+	assert.True(t, ok)
+	assert.Equal(t, "extension", name)
+
+	_, ok = tokens.Authenticate("de")
+	assert.False(t, ok)
+	_, ok = tokens.Authenticate("")
+	assert.False(t, ok)
+
+	_, ok = APITokens{"laptop": ""}.Authenticate("")
+	assert.False(t, ok)
+	assert.Equal(t, APITokens{"laptop": "abc"}, APITokens{"laptop": "abc", "phone": ""}.Merge(APITokens{"": "def"}))
+}
+
+func TestApiHandler_Authentication(t *testing.T) {
+	handler := LoggingHandler(NewAPIHandler(&fakeRadarItemsStorageService{}, APITokens{"poster": "xyz"}, false, make(chan bool, 100)))
+
+	req := httptest.NewRequest(http.MethodGet, apiPrefix, nil)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, `Bearer realm="radar"`, rr.Header().Get("WWW-Authenticate"))
+
+	req = httptest.NewRequest(http.MethodGet, apiPrefix, nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, apiPrefix, nil)
+	req.Header.Set("Authorization", "Bearer xyz")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
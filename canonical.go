@@ -0,0 +1,76 @@
+package radar
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters which identify how someone found a link, not what it links to.
+var trackingParams = map[string]bool{
+	"fbclid": true,
+	"gclid":  true,
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return trackingParams[name] || strings.HasPrefix(name, "utm_")
+}
+
+// canonicalizeURL normalizes a URL so that links to the same page compare equal. It lowercases
+// the scheme and host, drops "www.", default ports, trailing slashes, fragments and tracking
+// parameters, and sorts the remaining query parameters.
+//
+// The canonical URL is only used for comparison. Items keep the URL they were submitted with.
+func canonicalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host = host + ":" + port
+	}
+	u.Host = host
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	query := u.Query()
+	for name := range query {
+		if isTrackingParam(name) {
+			query.Del(name)
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// DuplicateRadarItemError is returned by Create when an unchecked item with the same canonical URL already exists.
+type DuplicateRadarItemError struct {
+	// Existing is the item already on the radar.
+	Existing RadarItem
+}
+
+func (e *DuplicateRadarItemError) Error() string {
+	return fmt.Sprintf("%s is already on the radar", e.Existing.URL)
+}
+
+// checkDuplicate returns a *DuplicateRadarItemError if any of the items has the same canonical URL as rawURL.
+func checkDuplicate(rawURL string, items ...[]RadarItem) error {
+	canonicalURL := canonicalizeURL(rawURL)
+	for _, list := range items {
+		for _, item := range list {
+			if canonicalizeURL(item.URL) == canonicalURL {
+				return &DuplicateRadarItemError{Existing: item}
+			}
+		}
+	}
+	return nil
+}
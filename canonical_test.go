@@ -0,0 +1,35 @@
+package radar
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_canonicalizeURL(t *testing.T) {
+	testcases := map[string]string{
+		"https://jvns.ca":      "https://jvns.ca",
+		"https://jvns.ca/":     "https://jvns.ca",
+		"HTTPS://WWW.JVNS.CA/": "https://jvns.ca",
+		"https://jvns.ca:443/blog/?utm_source=hn&utm_medium=email#comments": "https://jvns.ca/blog",
+		"https://example.com/watch?v=abc&fbclid=xyz&gclid=123&t=2":          "https://example.com/watch?t=2&v=abc",
+		"http://example.com:8080/path/":                                     "http://example.com:8080/path",
+		"not a url":                                                         "not a url",
+	}
+	for input, expected := range testcases {
+		assert.Equal(t, expected, canonicalizeURL(input), input)
+	}
+}
+
+func Test_checkDuplicate(t *testing.T) {
+	items := []RadarItem{{URL: "https://jvns.ca/blog/?utm_source=hn", Title: "Julia Evans"}}
+
+	assert.NoError(t, checkDuplicate("https://byparker.com", items))
+
+	err := checkDuplicate("https://www.jvns.ca/blog#top", nil, items)
+	var duplicateErr *DuplicateRadarItemError
+	if assert.True(t, errors.As(err, &duplicateErr)) {
+		assert.Equal(t, items[0], duplicateErr.Existing)
+	}
+}
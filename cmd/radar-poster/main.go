@@ -184,6 +184,7 @@ func create(conf config) http.HandlerFunc {
 		form := url.Values{}
 		form.Set("url", r.FormValue("url"))
 		form.Set("title", r.FormValue("title"))
+		form.Set("source", radar.SourcePoster)
 
 		client := &http.Client{Timeout: 5 * time.Second}
 		req, err := http.NewRequest(http.MethodPost, conf.RadarItemsServiceURL.String(), strings.NewReader(form.Encode()))
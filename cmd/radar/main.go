@@ -3,11 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -26,39 +28,113 @@ func getMailgunService() radar.MailgunService {
 	return radar.NewMailgunService(mg, os.Getenv("MG_FROM_EMAIL"))
 }
 
-// radarGenerator handles the signals and filters so only triggers at the given hour of day generates a new radar issue.
-func radarGenerator(radarItemsService radar.RadarItemsService, trigger chan os.Signal, hourToGenerateRadar string, radarGeneratedChan chan bool) {
-	if len(hourToGenerateRadar) != 2 {
-		radar.Printf("NOT generating radar. Hour to generate is not in 24-hr time: '%s'", hourToGenerateRadar)
-		return
+// envInt returns the environment variable as an integer, or 0 if it's unset or invalid.
+func envInt(name string) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return 0
 	}
+	return value
+}
 
-	mention := os.Getenv("RADAR_MENTION")
-	if mention == "" {
+// radarGenerator generates a new radar issue at each time in the schedule, and whenever it receives SIGUSR2.
+// On startup, it catches up on a run missed while the process was down.
+// If summaryPeriod is set, the first radar of each week or month is followed by a summary of the last one.
+func radarGenerator(radarItemsService radar.RadarItemsStorageService, schedule *radar.Schedule, trigger chan os.Signal, opts radar.DigestOptions, summaryPeriod string, radarGeneratedChan chan bool) {
+	opts.Mention = os.Getenv("RADAR_MENTION")
+	if opts.Mention == "" {
 		radar.Println("RADAR_MENTION is empty. Just so you know.")
 	}
 
-	radar.Printf("Will generate radar at %s:00 every day.", hourToGenerateRadar)
+	radar.Printf("Will generate radar on the schedule %s.", schedule)
+	if summaryPeriod != "" {
+		radar.Printf("Will generate a %s summary too.", summaryPeriod)
+	}
+
+	if latest, err := latestDigestTime(radarItemsService); err != nil {
+		radar.Printf("Couldn't tell when the radar was last generated, so not catching up: %#v", err)
+	} else if missed, ok := schedule.Missed(latest, time.Now()); ok {
+		radar.Printf("Missed the radar scheduled for %s, so generating it now.", missed)
+		generateScheduledRadar(radarItemsService, schedule, missed, opts, summaryPeriod, radarGeneratedChan)
+	}
 
-	for signal := range trigger {
-		thisHour := time.Now().Format("15")
-		if thisHour == hourToGenerateRadar || signal == syscall.SIGUSR2 {
+	for {
+		next := schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
 			radar.Println("The time has come: let's generate the radar!")
-			generateRadar(radarItemsService, mention)
+			generateScheduledRadar(radarItemsService, schedule, next, opts, summaryPeriod, radarGeneratedChan)
+		case signal, ok := <-trigger:
+			timer.Stop()
+			if !ok {
+				return
+			}
+			radar.Printf("Received %s: let's generate the radar!", signal)
+			generateRadar(radarItemsService, opts)
 			radarGeneratedChan <- true
-		} else {
-			radar.Printf("Wrong hour to generate! %s != %s", thisHour, hourToGenerateRadar)
 		}
 	}
 }
 
-// generateRadar generates a new radar issue and logs it, or any errors.
-func generateRadar(radarItemsService radar.RadarItemsService, mention string) {
-	issue, err := radar.GenerateRadarIssue(radarItemsService, mention)
+// latestDigestTime returns when the latest radar issue was generated.
+func latestDigestTime(radarItemsService radar.RadarItemsStorageService) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	digest, err := radarItemsService.GetDigest(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return digest.CreatedAt, nil
+}
+
+// generateScheduledRadar generates the radar scheduled for scheduledAt, unless it's already been
+// generated since then, e.g. before a restart.
+func generateScheduledRadar(radarItemsService radar.RadarItemsStorageService, schedule *radar.Schedule, scheduledAt time.Time, opts radar.DigestOptions, summaryPeriod string, radarGeneratedChan chan bool) {
+	if latest, err := latestDigestTime(radarItemsService); err != nil {
+		radar.Printf("Couldn't tell when the radar was last generated: %#v", err)
+	} else if !latest.Before(scheduledAt) {
+		radar.Printf("The radar scheduled for %s was already generated at %s.", scheduledAt, latest)
+		return
+	}
+
+	generated := generateRadar(radarItemsService, opts)
+	radarGeneratedChan <- true
+
+	// Only summarize alongside a new digest, so schedules which run several times a day
+	// summarize once.
+	if scheduledAt := scheduledAt.In(schedule.Location()); generated && radar.IsSummaryDue(summaryPeriod, scheduledAt) {
+		summaryOpts := radar.NewSummaryOptions(summaryPeriod, scheduledAt)
+		summaryOpts.Mention = opts.Mention
+		generateSummary(radarItemsService, summaryOpts)
+	}
+}
+
+// generateRadar generates a new radar issue and logs it, or any errors. If today's issue
+// already exists or another process is generating it, it logs that nothing was done. It
+// returns true if a new issue was generated.
+func generateRadar(radarItemsService radar.RadarItemsStorageService, opts radar.DigestOptions) bool {
+	issue, err := radar.GenerateRadarIssue(radarItemsService, opts)
+	switch {
+	case err == nil:
+		radar.Printf("Generated new radar issue: %s", issue.URL)
+		return true
+	case errors.Is(err, radar.ErrDigestAlreadyGenerated), errors.Is(err, radar.ErrDigestGenerationInProgress):
+		radar.Printf("Not generating a new radar issue: %v", err)
+	default:
+		radar.Printf("Couldn't generate new radar issue: %#v", err)
+	}
+	return false
+}
+
+// generateSummary generates a summary of the radar's activity and logs it, or any errors.
+func generateSummary(radarItemsService radar.RadarItemsStorageService, opts radar.SummaryOptions) {
+	summary, err := radar.GenerateRadarSummary(radarItemsService, opts)
 	if err == nil {
-		radar.Printf("Generated new radar issue: %s", *issue.HTMLURL)
+		radar.Printf("Generated radar summary %q: %s", summary.Title, summary.URL)
 	} else {
-		radar.Printf("Couldn't generate new radar issue: %#v", err)
+		radar.Printf("Couldn't generate radar summary: %#v", err)
 	}
 }
 
@@ -68,26 +144,99 @@ func main() {
 	var debug bool
 	flag.BoolVar(&debug, "debug", os.Getenv("DEBUG") == "", "Whether to print debugging messages.")
 	var hourToGenerateRadar string
-	flag.StringVar(&hourToGenerateRadar, "hour", "03", "Hour of day (01-23) to generate the radar message.")
+	flag.StringVar(&hourToGenerateRadar, "hour", "03", "Hour of day (00-23) to generate the radar message. Ignored if -schedule is set.")
+	var scheduleExpression string
+	flag.StringVar(&scheduleExpression, "schedule", os.Getenv("RADAR_SCHEDULE"), "Cron expression for when to generate the radar, e.g. \"30 7 * * 1-5\" for 07:30 on weekdays.")
+	var timezone string
+	flag.StringVar(&timezone, "timezone", os.Getenv("RADAR_TIMEZONE"), "IANA time zone for the schedule, e.g. America/Los_Angeles. Defaults to the local time zone.")
 	var feedConfigPath string
 	flag.StringVar(&feedConfigPath, "feedConfig", "", "Path to the feed config.")
+	var sqlitePath string
+	flag.StringVar(&sqlitePath, "sqlite", os.Getenv("RADAR_SQLITE_PATH"), "Path to a SQLite database to store radar items in instead of GitHub.")
+	var apiTokensPath string
+	flag.StringVar(&apiTokensPath, "apiTokens", "", "Path to a JSON file mapping API token names to tokens.")
+	var filesPath string
+	flag.StringVar(&filesPath, "files", os.Getenv("RADAR_FILES_PATH"), "Path to a directory to store radar items (JSONL) and digests (Markdown) in instead of GitHub.")
+	var groupBy string
+	flag.StringVar(&groupBy, "groupBy", os.Getenv("RADAR_GROUP_BY"), "Group each day's links by host, tag or week. Blank for no grouping.")
+	var expireAfterDays int
+	flag.IntVar(&expireAfterDays, "expireAfterDays", envInt("RADAR_EXPIRE_AFTER_DAYS"), "Stop carrying over links added more than this many days ago. 0 to keep them forever.")
+	var summaryPeriod string
+	flag.StringVar(&summaryPeriod, "summary", os.Getenv("RADAR_SUMMARY"), "Also generate a weekly or monthly summary of what was added, read and expired. Blank for none.")
 	flag.Parse()
 
+	if !radar.IsValidGroupBy(groupBy) {
+		radar.Printf("fatal: -groupBy must be host, tag, week or blank, got %q", groupBy)
+		os.Exit(1)
+	}
+	if summaryPeriod != "" && !radar.IsValidSummaryPeriod(summaryPeriod) {
+		radar.Printf("fatal: -summary must be weekly, monthly or blank, got %q", summaryPeriod)
+		os.Exit(1)
+	}
+	var schedule *radar.Schedule
+	var err error
+	if scheduleExpression != "" {
+		schedule, err = radar.ParseSchedule(scheduleExpression, timezone)
+	} else {
+		schedule, err = radar.ScheduleForHour(hourToGenerateRadar, timezone)
+	}
+	if err != nil {
+		radar.Printf("fatal: %v", err)
+		os.Exit(1)
+	}
+
 	grohl.SetLogger(grohl.NewIoLogger(os.Stderr))
 	grohl.SetStatter(nil, 0, "")
 
 	mux := http.NewServeMux()
 
-	githubToken := os.Getenv("GITHUB_ACCESS_TOKEN")
-	radarRepo := os.Getenv("RADAR_REPO")
-	if radarRepo == "" {
-		radar.Println("fatal: RADAR_REPO not set.")
-		os.Exit(1)
+	var radarItemsService radar.RadarItemsStorageService
+	if sqlitePath != "" {
+		sqliteService, err := radar.NewSQLiteRadarItemsService(sqlitePath)
+		if err != nil {
+			radar.Printf("fatal: couldn't open sqlite database at %q: %+v", sqlitePath, err)
+			os.Exit(1)
+		}
+		radarItemsService = sqliteService
+	} else if filesPath != "" {
+		fileService, err := radar.NewFileRadarItemsService(filesPath)
+		if err != nil {
+			radar.Printf("fatal: couldn't use %q for radar files: %+v", filesPath, err)
+			os.Exit(1)
+		}
+		radarItemsService = fileService
+	} else {
+		radarRepo := os.Getenv("RADAR_REPO")
+		if radarRepo == "" {
+			radar.Println("fatal: RADAR_REPO not set.")
+			os.Exit(1)
+		}
+		appConfig, err := radar.GitHubAppConfigFromEnv()
+		if err != nil {
+			radar.Printf("fatal: invalid GitHub App configuration: %+v", err)
+			os.Exit(1)
+		}
+		enterprise, err := radar.GitHubEnterpriseFromEnv()
+		if err != nil {
+			radar.Printf("fatal: invalid GitHub Enterprise configuration: %+v", err)
+			os.Exit(1)
+		}
+		githubClient, err := radar.NewGitHubClientFromEnv()
+		if err != nil {
+			radar.Printf("fatal: couldn't create GitHub client: %+v", err)
+			os.Exit(1)
+		}
+		if appConfig != nil {
+			radar.Printf("Authenticating to GitHub as GitHub App %s", appConfig)
+		}
+		if enterprise != nil {
+			radar.Printf("Using GitHub Enterprise Server at %s", enterprise.BaseURL)
+		}
+		radarRepoPieces := strings.Split(radarRepo, "/")
+		radarItemsService = radar.NewRadarItemsService(githubClient, radarRepoPieces[0], radarRepoPieces[1], os.Getenv("RADAR_LABEL"))
 	}
 
 	radarGeneratedChan := make(chan bool, 100)
-	radarRepoPieces := strings.Split(radarRepo, "/")
-	radarItemsService := radar.NewRadarItemsService(radar.NewGitHubClient(githubToken), radarRepoPieces[0], radarRepoPieces[1])
 
 	emailHandler := radar.NewEmailHandler(
 		radarItemsService, // RadarItemsService
@@ -99,7 +248,23 @@ func main() {
 	mux.Handle("/emails", emailHandler)
 	mux.Handle("/email", emailHandler)
 
-	apiHandler := radar.NewAPIHandler(radarItemsService, debug, radarGeneratedChan)
+	apiTokens, err := radar.ParseAPITokens(os.Getenv("RADAR_API_TOKENS"))
+	if err != nil {
+		radar.Printf("fatal: couldn't parse RADAR_API_TOKENS: %v", err)
+		os.Exit(1)
+	}
+	if apiTokensPath != "" {
+		fileTokens, err := radar.LoadAPITokensFile(apiTokensPath)
+		if err != nil {
+			radar.Printf("fatal: couldn't load API tokens: %v", err)
+			os.Exit(1)
+		}
+		apiTokens = apiTokens.Merge(fileTokens)
+	}
+	if len(apiTokens) == 0 {
+		radar.Println("No API tokens configured. Anyone who can reach /api/radar_items can use it.")
+	}
+	apiHandler := radar.NewAPIHandler(radarItemsService, apiTokens, debug, radarGeneratedChan)
 	mux.Handle("/api/", apiHandler)
 
 	mux.Handle("/health", radar.NewHealthHandler(radarItemsService))
@@ -116,7 +281,14 @@ func main() {
 			log.Fatal("exiting")
 		}
 		feedHandler := radar.NewFeedHandler(radarItemsService, *feedConfig, radarGeneratedChan)
+		mux.Handle("/feed", feedHandler)
 		mux.Handle("/feed.atom", feedHandler)
+		mux.Handle("/feed.rss", feedHandler)
+		mux.Handle("/feed.json", feedHandler)
+		mux.Handle("/feed/done", feedHandler)
+		mux.Handle("/feed/done.atom", feedHandler)
+		mux.Handle("/feed/done.rss", feedHandler)
+		mux.Handle("/feed/done.json", feedHandler)
 		go feedHandler.Start()
 	} else {
 		go func() {
@@ -130,19 +302,16 @@ func main() {
 
 	// Start the radarGenerator.
 	radarC := make(chan os.Signal, 1)
-	go radarGenerator(radarItemsService, radarC, hourToGenerateRadar, radarGeneratedChan)
+	digestOptions := radar.DigestOptions{
+		GroupBy:     groupBy,
+		ExpireAfter: time.Duration(expireAfterDays) * 24 * time.Hour,
+		Location:    schedule.Location(),
+	}
+	go radarGenerator(radarItemsService, schedule, radarC, digestOptions, summaryPeriod, radarGeneratedChan)
 
 	// Sending SIGUSR2 to this process generates a radar.
 	signal.Notify(radarC, syscall.SIGUSR2)
 
-	// Prompt radarGenerator to do something every 1 hour.
-	ticker := time.NewTicker(1 * time.Hour)
-	go func() {
-		for range ticker.C {
-			radarC <- syscall.SIGUSR1
-		}
-	}()
-
 	radar.Println("Starting server on", binding)
 	server := &http.Server{Addr: binding, Handler: radar.LoggingHandler(mux)}
 
@@ -154,8 +323,8 @@ func main() {
 		radar.Printf("Received signal %#v!", sig)
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
+		signal.Stop(radarC)
 		close(radarC)
-		ticker.Stop()
 		radar.Println("Shutting down radar items service...")
 		radarItemsService.Shutdown(ctx)
 		emailHandler.Shutdown(ctx)
@@ -2,21 +2,18 @@ package radar
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/mail"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"mvdan.cc/xurls/v2"
 )
 
-type RadarItemsStorageService interface {
-	// Store a new radar item.
-	Create(ctx context.Context, m RadarItem) error
-	// Shut down the service.
-	Shutdown(ctx context.Context)
-}
-
 func NewEmailHandler(radarItemsService RadarItemsStorageService, mailgunService MailgunService, allowedSenders []string, debug bool, RadarCreatedChan chan bool) EmailHandler {
 	return EmailHandler{
 		AllowedSenders:   allowedSenders,
@@ -25,6 +22,7 @@ func NewEmailHandler(radarItemsService RadarItemsStorageService, mailgunService
 		Mailgun:          mailgunService,
 		CreateQueue:      make(chan createRequest, 10),
 		RadarCreatedChan: RadarCreatedChan,
+		retries:          &emailRetries{},
 	}
 }
 
@@ -45,6 +43,16 @@ type EmailHandler struct {
 	CreateQueue chan createRequest
 
 	RadarCreatedChan chan bool
+
+	// Requests waiting to be retried.
+	retries *emailRetries
+}
+
+// emailRetries tracks requests which are waiting to go back on the CreateQueue, so they aren't
+// sent to it once it's closed.
+type emailRetries struct {
+	sync.Mutex
+	closed bool
 }
 
 type createRequest struct {
@@ -55,29 +63,105 @@ type createRequest struct {
 	subject string
 
 	url string
+
+	tags []string
+
+	note string
+
+	// attempt is how many times saving this request has been tried.
+	attempt int
+
+	// err is why the last attempt failed.
+	err error
 }
 
-// Start polls on the CreateQueue and runs
+// emailCreateAttempts is how many times to try saving an emailed link before replying with an error.
+const emailCreateAttempts = 3
+
+// emailCreateBackoff is the wait between attempts to save an emailed link. It doubles each time.
+var emailCreateBackoff = 5 * time.Second
+
+// Start polls on the CreateQueue and saves each request, replying once it's saved or has
+// failed too many times. Failed requests go back on the queue after a delay, so a slow or
+// failing backend doesn't hold up the others.
 func (h EmailHandler) Start() {
 	for req := range h.CreateQueue {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		if err := h.RadarItems.Create(ctx, RadarItem{URL: req.url}); err != nil {
-			Printf("error saving '%s': %#v %+v", req.url, err, err)
-			h.Mailgun.SendReply(req, "Could not save "+req.url+" to the radar: "+err.Error())
-		} else {
-			h.Mailgun.SendReply(req, "Added "+req.url+" to the radar.")
-			Printf("saved url=%s to radar", req.url)
-			h.RadarCreatedChan <- true
+		reply, retry := h.save(&req)
+		if retry {
+			h.retryLater(req)
+			continue
 		}
-		cancel()
+		h.Mailgun.SendReply(req, reply)
 	}
 }
 
+// save makes one attempt to add the emailed link to the radar. It returns the reply to send,
+// or true if the attempt failed and should be retried.
+func (h EmailHandler) save(req *createRequest) (string, bool) {
+	req.attempt++
+	item := RadarItem{URL: req.url, Tags: req.tags, Note: req.note, Source: SourceEmail, SubmittedBy: emailAddress(req.fromEmail)}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	_, err := h.RadarItems.Create(ctx, item)
+	cancel()
+
+	var duplicateErr *DuplicateRadarItemError
+	if errors.As(err, &duplicateErr) && req.attempt > 1 {
+		// An earlier attempt saved it after all.
+		err = nil
+	} else if errors.As(err, &duplicateErr) {
+		Printf("skipping duplicate url=%s", req.url)
+		return req.url + " is already on the radar (as " + duplicateErr.Existing.URL + "), so it was not added again.", false
+	}
+	if err == nil {
+		Printf("saved url=%s to radar", req.url)
+		h.RadarCreatedChan <- true
+		return "Added " + req.url + " to the radar.", false
+	}
+
+	Printf("error saving '%s' (attempt %d of %d): %#v %+v", req.url, req.attempt, emailCreateAttempts, err, err)
+	req.err = err
+	if req.attempt < emailCreateAttempts {
+		return "", true
+	}
+	return emailSaveFailedReply(*req), false
+}
+
+func emailSaveFailedReply(req createRequest) string {
+	return "Could not save " + req.url + " to the radar: " + req.err.Error()
+}
+
+// retryLater puts the request back on the CreateQueue after a backoff which doubles with each
+// attempt. If the handler shuts down first, it replies with the last error instead.
+func (h EmailHandler) retryLater(req createRequest) {
+	time.AfterFunc(emailCreateBackoff<<(req.attempt-1), func() {
+		h.retries.Lock()
+		defer h.retries.Unlock()
+		if h.retries.closed {
+			Printf("shutting down, so not retrying url=%s", req.url)
+			h.Mailgun.SendReply(req, emailSaveFailedReply(req))
+			return
+		}
+		h.CreateQueue <- req
+	})
+}
+
 func (h EmailHandler) Shutdown(ctx context.Context) {
+	h.retries.Lock()
+	h.retries.closed = true
 	close(h.CreateQueue)
+	h.retries.Unlock()
 	h.RadarItems.Shutdown(ctx)
 }
 
+// emailAddress returns the bare address from a From header, e.g. "parker@example.com" for
+// "Parker <parker@example.com>", or the header as-is if it can't be parsed.
+func emailAddress(from string) string {
+	if address, err := mail.ParseAddress(from); err == nil {
+		return address.Address
+	}
+	return from
+}
+
 func (h EmailHandler) IsAllowedSender(sender string) bool {
 	email, err := mail.ParseAddress(sender)
 	if err != nil {
@@ -128,14 +212,42 @@ func (h EmailHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Printf("form: %#v", r.Form)
 	}
 
+	subject := r.FormValue("Subject")
+	tags, note := parseEmailTagsAndNote(subject, emailBody)
+	if h.Debug {
+		Printf("tags: %#v note: %#v", tags, note)
+	}
+
 	for _, url := range urls {
 		h.CreateQueue <- createRequest{
 			fromEmail: r.FormValue("From"),
 			messageID: r.FormValue("Message-Id"),
-			subject:   r.FormValue("Subject"),
+			subject:   subject,
 			url:       url,
+			tags:      tags,
+			note:      note,
 		}
 	}
 
 	http.Error(w, fmt.Sprintf("added %d urls to today's radar", len(urls)), http.StatusCreated)
 }
+
+var (
+	emailSubjectPrefixRegexp = regexp.MustCompile(`(?i)^((re|fwd?):\s*)+`)
+	emailNoteLineRegexp      = regexp.MustCompile(`(?im)^\s*note:\s*(.+)$`)
+)
+
+// parseEmailTagsAndNote reads the #tags from an email's subject and body, and its note from a
+// "note:" line in the body or, failing that, the rest of the subject.
+func parseEmailTagsAndNote(subject, body string) ([]string, string) {
+	tags := extractHashtags(subject + "\n" + body)
+
+	if matches := emailNoteLineRegexp.FindStringSubmatch(body); matches != nil {
+		return tags, strings.TrimSpace(matches[1])
+	}
+
+	note := xurls.Strict().ReplaceAllString(subject, " ")
+	note = emailHashtagRegexp.ReplaceAllString(note, " ")
+	note = emailSubjectPrefixRegexp.ReplaceAllString(strings.TrimSpace(note), "")
+	return tags, strings.Join(strings.Fields(note), " ")
+}
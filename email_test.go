@@ -0,0 +1,99 @@
+package radar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailHandler_save(t *testing.T) {
+	unavailable := errors.New("502 Bad Gateway")
+	newRequest := func() *createRequest {
+		return &createRequest{fromEmail: "Parker <parker@example.com>", url: "https://jvns.ca"}
+	}
+
+	// Transient failures are retried.
+	storage := &fakeRadarItemsStorageService{createErrs: []error{unavailable, unavailable}}
+	handler := NewEmailHandler(storage, MailgunService{}, nil, false, make(chan bool, 10))
+	req := newRequest()
+	for attempt := 1; attempt < emailCreateAttempts; attempt++ {
+		reply, retry := handler.save(req)
+		assert.True(t, retry)
+		assert.Empty(t, reply)
+	}
+	reply, retry := handler.save(req)
+	assert.False(t, retry)
+	assert.Equal(t, "Added https://jvns.ca to the radar.", reply)
+	if assert.Len(t, storage.newItems, 1) {
+		assert.Equal(t, "parker@example.com", storage.newItems[0].SubmittedBy)
+	}
+
+	// An earlier attempt which timed out may have saved it after all.
+	reply, retry = handler.save(req)
+	assert.False(t, retry)
+	assert.Equal(t, "Added https://jvns.ca to the radar.", reply)
+
+	// Only so many times.
+	storage = &fakeRadarItemsStorageService{createErrs: []error{unavailable, unavailable, unavailable}}
+	handler = NewEmailHandler(storage, MailgunService{}, nil, false, make(chan bool, 10))
+	req = newRequest()
+	for attempt := 1; attempt < emailCreateAttempts; attempt++ {
+		_, retry := handler.save(req)
+		assert.True(t, retry)
+	}
+	reply, retry = handler.save(req)
+	assert.False(t, retry)
+	assert.Equal(t, "Could not save https://jvns.ca to the radar: 502 Bad Gateway", reply)
+	assert.Empty(t, storage.newItems)
+
+	// Duplicates aren't retried.
+	storage = &fakeRadarItemsStorageService{newItems: []RadarItem{{URL: "https://jvns.ca"}}}
+	handler = NewEmailHandler(storage, MailgunService{}, nil, false, make(chan bool, 10))
+	reply, retry = handler.save(newRequest())
+	assert.False(t, retry)
+	assert.Equal(t, "https://jvns.ca is already on the radar (as https://jvns.ca), so it was not added again.", reply)
+}
+
+func TestEmailHandler_Start(t *testing.T) {
+	defer func(backoff time.Duration) { emailCreateBackoff = backoff }(emailCreateBackoff)
+	emailCreateBackoff = 10 * time.Millisecond
+	unavailable := errors.New("502 Bad Gateway")
+
+	storage := &fakeRadarItemsStorageService{createErrs: []error{unavailable}}
+	handler := NewEmailHandler(storage, MailgunService{}, nil, false, make(chan bool, 10))
+	go handler.Start()
+	defer handler.Shutdown(context.Background())
+
+	// The first fails and waits to be retried, without holding up the second.
+	handler.CreateQueue <- createRequest{url: "https://jvns.ca"}
+	handler.CreateQueue <- createRequest{url: "https://byparker.com"}
+
+	assert.Eventually(t, func() bool {
+		storage.Lock()
+		defer storage.Unlock()
+		return len(storage.newItems) == 2
+	}, time.Second, time.Millisecond)
+	storage.Lock()
+	defer storage.Unlock()
+	assert.Equal(t, []string{"https://byparker.com", "https://jvns.ca"}, []string{storage.newItems[0].URL, storage.newItems[1].URL})
+}
+
+func Test_parseEmailTagsAndNote(t *testing.T) {
+	testCases := []struct {
+		subject, body string
+		tags          []string
+		note          string
+	}{
+		{"Fwd: Great read #golang", "https://go.dev/blog", []string{"golang"}, "Great read"},
+		{"Re: https://go.dev/blog", "https://go.dev/blog #reading\nnote: for the weekend", []string{"reading"}, "for the weekend"},
+		{"", "https://go.dev/blog", nil, ""},
+	}
+	for _, testCase := range testCases {
+		tags, note := parseEmailTagsAndNote(testCase.subject, testCase.body)
+		assert.Equal(t, testCase.tags, tags, testCase.subject)
+		assert.Equal(t, testCase.note, note, testCase.subject)
+	}
+}
@@ -3,34 +3,101 @@ package radar
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"mime"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/feeds"
 )
 
+// legacyFeedTokenName is the name given to FeedConfig.APIKey.
+const legacyFeedTokenName = "apiKey"
+
+// doneFeedWindow is how far back the done feed goes.
+const doneFeedWindow = 30 * 24 * time.Hour
+
+// defaultFeedCacheTTL is how long a rendered feed is served before it's rebuilt, unless the
+// radar changes first.
+const defaultFeedCacheTTL = 15 * time.Minute
+
 type FeedConfig struct {
 	Title       string
 	URL         string
 	Description string
 	AuthorName  string
-	APIKey      string
+	// Tokens are the named tokens which may read the feed, e.g. {"parker-laptop": "abc"}.
+	// Remove a token to revoke it.
+	Tokens APITokens
+	// APIKey is a single shared token, accepted alongside Tokens under the name "apiKey".
+	// Deprecated: use Tokens.
+	APIKey string
+	// CacheTTLSeconds is how long to serve a rendered feed before rebuilding it. Defaults to 15 minutes.
+	CacheTTLSeconds int
+}
+
+// renderedFeed is a feed rendered in one format, ready to serve.
+type renderedFeed struct {
+	body []byte
+	etag string
+}
+
+// feedCache holds the feed rendered in every format.
+type feedCache struct {
+	rendered     map[string]renderedFeed
+	lastModified time.Time
+	expiresAt    time.Time
 }
 
 type FeedHandler struct {
-	radarItems         RadarItemsService
-	feed               *feeds.Feed
-	apiToken           string
-	cache              bytes.Buffer
+	radarItems RadarItemsStorageService
+	feed       feeds.Feed
+	tokens     APITokens
+	// requireToken is set if any tokens were configured, even if they were all ignored as
+	// blank, so that the feed isn't opened up by mistake.
+	requireToken       bool
+	cacheTTL           time.Duration
 	radarGeneratedChan chan bool
+
+	// mu guards everything below.
+	mu sync.RWMutex
+	// caches hold each feed (radarFeed or doneFeed) rendered in every format. A feed's cache
+	// is missing until it's first requested, and after it's reset.
+	caches map[string]*feedCache
+	// lastModified is when each rendered feed last changed, and etags are the ETags it had
+	// then, by feed and format. Both survive resets.
+	lastModified map[string]time.Time
+	etags        map[string]string
+	// firstSeen records when items with no add date were first put in the feed, by feed item ID,
+	// so that their timestamps don't change on every fetch.
+	firstSeen map[string]time.Time
 }
 
-// NewFeedHandler creates a new handler which will respond with an Atom feed of radar items.
-func NewFeedHandler(radarItemsService RadarItemsService, config FeedConfig, radarGeneratedChan chan bool) *FeedHandler {
+// NewFeedHandler creates a new handler which will respond with an Atom, RSS or JSON feed of radar items.
+func NewFeedHandler(radarItemsService RadarItemsStorageService, config FeedConfig, radarGeneratedChan chan bool) *FeedHandler {
+	cacheTTL := defaultFeedCacheTTL
+	if config.CacheTTLSeconds > 0 {
+		cacheTTL = time.Duration(config.CacheTTLSeconds) * time.Second
+	}
+	tokens := APITokens{}
+	if config.APIKey != "" {
+		tokens[legacyFeedTokenName] = config.APIKey
+	}
+	for name, token := range config.Tokens {
+		if name == "" || token == "" {
+			Printf("Ignoring feed token %q: its name and value must both be set.", name)
+		}
+	}
+	tokens = tokens.Merge(config.Tokens)
 	return &FeedHandler{
 		radarItems: radarItemsService,
-		feed: &feeds.Feed{
+		feed: feeds.Feed{
 			Title:       config.Title,
 			Link:        &feeds.Link{Href: config.URL},
 			Description: config.Description,
@@ -38,65 +105,319 @@ func NewFeedHandler(radarItemsService RadarItemsService, config FeedConfig, rada
 			Created:     time.Now(),
 		},
 		radarGeneratedChan: radarGeneratedChan,
-		apiToken:           config.APIKey,
+		tokens:             tokens,
+		requireToken:       config.APIKey != "" || len(config.Tokens) > 0,
+		cacheTTL:           cacheTTL,
+		caches:             map[string]*feedCache{},
+		lastModified:       map[string]time.Time{},
+		etags:              map[string]string{},
+		firstSeen:          map[string]time.Time{},
+	}
+}
+
+const (
+	// radarFeed lists the items on the radar, e.g. /feed.atom.
+	radarFeed = "radar"
+	// doneFeed lists the items checked off in the last doneFeedWindow, e.g. /feed/done.atom.
+	doneFeed = "done"
+)
+
+// feedNameForRequest returns doneFeed for requests to /feed/done, and radarFeed otherwise.
+func feedNameForRequest(r *http.Request) string {
+	base := path.Base(r.URL.Path)
+	if strings.TrimSuffix(base, path.Ext(base)) == doneFeed {
+		return doneFeed
 	}
+	return radarFeed
 }
 
-func (h FeedHandler) Start() {
-	for _ = range h.radarGeneratedChan {
+// Start resets the cache whenever the radar changes. It returns when radarGeneratedChan is closed.
+func (h *FeedHandler) Start() {
+	for range h.radarGeneratedChan {
 		h.ResetCache()
 	}
 }
 
+// feedItemID returns a stable ID for the item, so feed readers recognize it across fetches
+// and regenerations. Links which differ only by tracking parameters and the like share an ID.
+func feedItemID(item RadarItem) string {
+	return canonicalizeURL(item.URL)
+}
+
+// convertDoneRadarItemToFeedItem converts a checked-off item to a feed entry dated when it was checked off.
+func convertDoneRadarItemToFeedItem(item RadarItem) *feeds.Item {
+	feedItem := convertRadarItemToFeedItem(item)
+	feedItem.Created = item.CheckedAt
+	return feedItem
+}
+
+// convertRadarItemToFeedItem converts the item to a feed entry. Created is the time the item
+// was added to the radar, which is zero if it's unknown.
 func convertRadarItemToFeedItem(item RadarItem) *feeds.Item {
 	return &feeds.Item{
+		Id:          feedItemID(item),
 		Title:       item.GetTitle(),
 		Link:        &feeds.Link{Href: item.URL},
 		Description: item.GetHostname(),
 		Content:     item.GetFormatted(),
-		Created:     time.Now(),
+		Created:     item.AddedAt,
+		// The ID is the canonical URL, which may differ from the link.
+		IsPermaLink: "false",
 	}
 }
 
-func (h FeedHandler) ResetCache() {
-	h.cache.Reset()
+// feedFormat is a way of writing the feed, e.g. as Atom.
+type feedFormat struct {
+	contentType string
+	write       func(feed *feeds.Feed, w io.Writer) error
 }
 
-func (h *FeedHandler) populateCache(ctx context.Context) error {
-	h.ResetCache()
+// feedFormats are the supported feed formats, by file extension.
+var feedFormats = map[string]feedFormat{
+	"atom": {contentType: "application/atom+xml", write: (*feeds.Feed).WriteAtom},
+	"rss":  {contentType: "application/rss+xml", write: (*feeds.Feed).WriteRss},
+	"json": {contentType: "application/feed+json", write: (*feeds.Feed).WriteJSON},
+}
 
-	oldItems, newItems, err := h.radarItems.List(ctx)
+// feedFormatsByMediaType maps the media types accepted in an Accept header to feed formats.
+var feedFormatsByMediaType = map[string]string{
+	"application/atom+xml":  "atom",
+	"application/rss+xml":   "rss",
+	"application/feed+json": "json",
+	"application/json":      "json",
+	"application/xml":       "atom",
+	"text/xml":              "atom",
+}
+
+// defaultFeedFormat is served when the request doesn't ask for a particular format.
+const defaultFeedFormat = "atom"
+
+// negotiateFeedFormat returns the format for the request: the path's extension if it's one
+// of feedFormats, e.g. /feed.rss, otherwise the most preferred format in the Accept header.
+func negotiateFeedFormat(r *http.Request) string {
+	if ext := strings.TrimPrefix(path.Ext(r.URL.Path), "."); ext != "" {
+		if _, ok := feedFormats[ext]; ok {
+			return ext
+		}
+	}
+
+	format, bestQuality := defaultFeedFormat, 0.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		acceptedFormat, ok := feedFormatsByMediaType[mediaType]
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			quality = q
+		}
+		if quality > bestQuality {
+			format, bestQuality = acceptedFormat, quality
+		}
+	}
+	return format
+}
+
+// ResetCache discards the rendered feeds, so that the next request for each rebuilds it.
+func (h *FeedHandler) ResetCache() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.caches = map[string]*feedCache{}
+}
+
+// getCache returns the rendered feed, rebuilding it if it's been reset or has expired.
+func (h *FeedHandler) getCache(ctx context.Context, feedName string) (*feedCache, error) {
+	h.mu.RLock()
+	cache := h.caches[feedName]
+	h.mu.RUnlock()
+	if cache != nil && time.Now().Before(cache.expiresAt) {
+		return cache, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Another request may have rebuilt it while we waited for the lock.
+	if cache := h.caches[feedName]; cache != nil && time.Now().Before(cache.expiresAt) {
+		return cache, nil
+	}
+
+	cache, err := h.buildCache(ctx, feedName)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	h.caches[feedName] = cache
+	return cache, nil
+}
 
-	h.feed.Items = []*feeds.Item{}
+// radarFeedItems lists the radar items as feed entries. Entries without an add date are
+// given the time they were first seen. h.mu must be held.
+func (h *FeedHandler) radarFeedItems(ctx context.Context, now time.Time) ([]*feeds.Item, error) {
+	oldItems, newItems, err := h.radarItems.List(ctx)
+	if err != nil {
+		return nil, err
+	}
 
+	feedItems := []*feeds.Item{}
 	for _, item := range newItems {
-		h.feed.Items = append(h.feed.Items, convertRadarItemToFeedItem(item))
+		feedItems = append(feedItems, convertRadarItemToFeedItem(item))
 	}
-
 	for _, item := range oldItems {
-		h.feed.Items = append(h.feed.Items, convertRadarItemToFeedItem(item))
+		feedItems = append(feedItems, convertRadarItemToFeedItem(item))
+	}
+
+	current := map[string]bool{}
+	for _, feedItem := range feedItems {
+		current[feedItem.Id] = true
+		if feedItem.Created.IsZero() {
+			if _, ok := h.firstSeen[feedItem.Id]; !ok {
+				h.firstSeen[feedItem.Id] = now
+			}
+			feedItem.Created = h.firstSeen[feedItem.Id]
+		}
+	}
+	// Forget items which have left the radar.
+	for id := range h.firstSeen {
+		if !current[id] {
+			delete(h.firstSeen, id)
+		}
+	}
+	return feedItems, nil
+}
+
+// doneFeedItems lists the items checked off in the last doneFeedWindow as feed entries.
+func (h *FeedHandler) doneFeedItems(ctx context.Context, now time.Time) ([]*feeds.Item, error) {
+	doneItems, err := h.radarItems.ListDone(ctx, now.Add(-doneFeedWindow))
+	if err != nil {
+		return nil, err
 	}
 
-	return h.feed.WriteAtom(&h.cache)
+	feedItems := []*feeds.Item{}
+	for _, item := range doneItems {
+		feedItems = append(feedItems, convertDoneRadarItemToFeedItem(item))
+	}
+	return feedItems, nil
 }
 
-func (h FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.FormValue("tok") != h.apiToken {
+// buildCache lists the feed's items and renders it in every format. h.mu must be held.
+func (h *FeedHandler) buildCache(ctx context.Context, feedName string) (*feedCache, error) {
+	now := time.Now()
+	feed := h.feed
+	var err error
+	if feedName == doneFeed {
+		feed.Title = "Done: " + feed.Title
+		feed.Items, err = h.doneFeedItems(ctx, now)
+	} else {
+		feed.Items, err = h.radarFeedItems(ctx, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, feedItem := range feed.Items {
+		if feedItem.Created.After(feed.Updated) {
+			feed.Updated = feedItem.Created
+		}
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = feed.Created
+	}
+
+	cache := &feedCache{rendered: map[string]renderedFeed{}, expiresAt: now.Add(h.cacheTTL)}
+	changed := false
+	for name, format := range feedFormats {
+		var buf bytes.Buffer
+		if err := format.write(&feed, &buf); err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(buf.Bytes())
+		rendered := renderedFeed{body: buf.Bytes(), etag: `"` + hex.EncodeToString(sum[:16]) + `"`}
+		cache.rendered[name] = rendered
+		if key := feedName + "/" + name; h.etags[key] != rendered.etag {
+			h.etags[key] = rendered.etag
+			changed = true
+		}
+	}
+
+	// Readers which poll with If-Modified-Since shouldn't re-download an unchanged feed.
+	if changed {
+		h.lastModified[feedName] = now.Truncate(time.Second)
+	}
+	cache.lastModified = h.lastModified[feedName]
+	return cache, nil
+}
+
+// notModified returns true if the request's conditional headers show the client already has this version.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		return !lastModified.After(ifModifiedSince)
+	}
+	return false
+}
+
+// feedToken extracts the token from the request. Feed readers vary in what they support, so
+// it may be a bearer token, the password for HTTP basic auth, or the "tok" query parameter.
+func feedToken(r *http.Request) string {
+	if token, ok := bearerToken(r); ok {
+		return token
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	return r.FormValue("tok")
+}
+
+// authenticate checks the request's token, recording its name in the log context.
+// Any request is allowed if no tokens are configured.
+func (h *FeedHandler) authenticate(r *http.Request) bool {
+	if !h.requireToken {
+		return true
+	}
+	name, ok := h.tokens.Authenticate(feedToken(r))
+	if !ok {
+		return false
+	}
+	if logCtx := getLogContextOrNil(r); logCtx != nil {
+		logCtx.Add("token_name", name)
+	}
+	return true
+}
+
+func (h *FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authenticate(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="radar"`)
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
 
-	if err := h.populateCache(r.Context()); err != nil {
+	cache, err := h.getCache(r.Context(), feedNameForRequest(r))
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Header().Set("Content-Type", "application/atom+xml")
-	if _, err := io.Copy(w, &h.cache); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	name := negotiateFeedFormat(r)
+	rendered := cache.rendered[name]
+	w.Header().Set("Content-Type", feedFormats[name].contentType)
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("ETag", rendered.etag)
+	w.Header().Set("Last-Modified", cache.lastModified.UTC().Format(http.TimeFormat))
+	if notModified(r, rendered.etag, cache.lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(rendered.body)
 }
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v53/github"
 	"github.com/stretchr/testify/assert"
@@ -14,19 +16,11 @@ import (
 
 func TestFeedHandler_ServeHTTP(t *testing.T) {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
-		json.NewEncoder(w).Encode(&github.IssuesSearchResult{
-			Total:             github.Int(1),
-			IncompleteResults: github.Bool(false),
-			Issues: []*github.Issue{
-				{
-					Title:   github.String("Issue 123"),
-					HTMLURL: github.String("http://example.com/issue/123"),
-					Number:  github.Int(123),
-					Body:    github.String(testData.newStyleBody),
-				},
-			},
-		})
+	handleOpenRadarIssue(t, mux, "monalisa/diary", &github.Issue{
+		Title:   github.String("Issue 123"),
+		HTMLURL: github.String("http://example.com/issue/123"),
+		Number:  github.Int(123),
+		Body:    github.String(testData.newStyleBody),
 	})
 	mux.HandleFunc("/repos/monalisa/diary/issues/123/comments", func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode([]*github.IssueComment{
@@ -39,7 +33,7 @@ func TestFeedHandler_ServeHTTP(t *testing.T) {
 
 	ghClient := github.NewClient(nil)
 	ghClient.BaseURL = serverURL
-	radarItemsService := NewRadarItemsService(ghClient, "monalisa", "diary")
+	radarItemsService := NewRadarItemsService(ghClient, "monalisa", "diary", "")
 	feedConfig := FeedConfig{
 		Title:       "My Feed",
 		URL:         "http://example.com/feed.atom",
@@ -64,3 +58,229 @@ func TestFeedHandler_ServeHTTP(t *testing.T) {
 	assert.Contains(t, body, "Song Notes") // random item from testData
 	fmt.Println(body)
 }
+
+func TestFeedHandler_StableEntries(t *testing.T) {
+	addedAt := time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC)
+	storage := &fakeRadarItemsStorageService{
+		oldItems: []RadarItem{{URL: "https://jvns.ca/", Title: "Julia Evans"}},
+		newItems: []RadarItem{{URL: "https://www.byparker.com/?utm_source=email", Title: "By Parker", AddedAt: addedAt}},
+	}
+	h := NewFeedHandler(storage, FeedConfig{Title: "My Feed", URL: "http://example.com/feed.atom", APIKey: "foo"}, make(chan bool))
+
+	fetch := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/feed.atom?tok=foo", nil)
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		return recorder.Body.String()
+	}
+
+	body := fetch()
+	assert.Contains(t, body, "<id>https://byparker.com</id>")
+	assert.Contains(t, body, "<updated>2026-10-17T09:30:00Z</updated>")
+	assert.Contains(t, body, "<id>https://jvns.ca</id>")
+
+	// Entries without an add date keep the time they were first seen.
+	time.Sleep(time.Second)
+	h.ResetCache()
+	assert.Equal(t, body, fetch())
+}
+
+func Test_negotiateFeedFormat(t *testing.T) {
+	testCases := []struct {
+		path, accept, expected string
+	}{
+		{"/feed.atom", "", "atom"},
+		{"/feed.rss", "application/atom+xml", "rss"},
+		{"/feed.json", "", "json"},
+		{"/feed", "", "atom"},
+		{"/feed", "application/rss+xml", "rss"},
+		{"/feed", "application/feed+json, application/atom+xml;q=0.5", "json"},
+		{"/feed", "application/atom+xml;q=0.5, application/rss+xml;q=0.9", "rss"},
+		{"/feed", "text/html, */*;q=0.8", "atom"},
+	}
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+		req.Header.Set("Accept", testCase.accept)
+		assert.Equal(t, testCase.expected, negotiateFeedFormat(req), "%s Accept: %s", testCase.path, testCase.accept)
+	}
+}
+
+func TestFeedHandler_Formats(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{
+		newItems: []RadarItem{{URL: "https://jvns.ca", Title: "Julia Evans", AddedAt: time.Now()}},
+	}
+	h := NewFeedHandler(storage, FeedConfig{Title: "My Feed", URL: "http://example.com/feed", APIKey: "foo"}, make(chan bool))
+
+	for path, contentType := range map[string]string{
+		"/feed.atom": "application/atom+xml",
+		"/feed.rss":  "application/rss+xml",
+		"/feed.json": "application/feed+json",
+	} {
+		req := httptest.NewRequest(http.MethodGet, path+"?tok=foo", nil)
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+
+		assert.Equal(t, http.StatusOK, recorder.Code, path)
+		assert.Equal(t, contentType, recorder.Header().Get("Content-Type"), path)
+		assert.Contains(t, recorder.Body.String(), "Julia Evans", path)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/feed.json?tok=foo", nil)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	feed := struct {
+		Items []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"items"`
+	}{}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &feed))
+	if assert.Len(t, feed.Items, 1) {
+		assert.Equal(t, "https://jvns.ca", feed.Items[0].ID)
+	}
+}
+
+func TestFeedHandler_Cache(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{
+		newItems: []RadarItem{{URL: "https://jvns.ca", Title: "Julia Evans", AddedAt: time.Now()}},
+	}
+	radarGeneratedChan := make(chan bool)
+	h := NewFeedHandler(storage, FeedConfig{Title: "My Feed", URL: "http://example.com/feed", APIKey: "foo"}, radarGeneratedChan)
+	go h.Start()
+	defer close(radarGeneratedChan)
+
+	fetch := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/feed.atom?tok=foo", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// Concurrent requests share one build of the feed.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, http.StatusOK, fetch(nil).Code)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, storage.listCalls)
+
+	first := fetch(nil)
+	etag, lastModified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	assert.NotEmpty(t, etag)
+	assert.NotEmpty(t, lastModified)
+
+	assert.Equal(t, http.StatusNotModified, fetch(map[string]string{"If-None-Match": etag}).Code)
+	assert.Equal(t, http.StatusNotModified, fetch(map[string]string{"If-Modified-Since": lastModified}).Code)
+	assert.Equal(t, http.StatusOK, fetch(map[string]string{"If-None-Match": `"stale"`}).Code)
+
+	// Regenerating the radar rebuilds the feed, but an unchanged feed keeps its validators.
+	radarGeneratedChan <- true
+	radarGeneratedChan <- true // Wait for the first signal to be handled.
+	rebuilt := fetch(nil)
+	assert.Equal(t, 2, storage.listCalls)
+	assert.Equal(t, etag, rebuilt.Header().Get("ETag"))
+	assert.Equal(t, lastModified, rebuilt.Header().Get("Last-Modified"))
+
+	storage.Lock()
+	storage.newItems = append(storage.newItems, RadarItem{URL: "https://byparker.com", Title: "By Parker", AddedAt: time.Now()})
+	storage.Unlock()
+	h.ResetCache()
+	changed := fetch(map[string]string{"If-None-Match": etag})
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+	assert.Contains(t, changed.Body.String(), "By Parker")
+}
+
+func TestFeedHandler_Tokens(t *testing.T) {
+	h := NewFeedHandler(&fakeRadarItemsStorageService{}, FeedConfig{
+		Title:  "My Feed",
+		URL:    "http://example.com/feed",
+		APIKey: "shared",
+		Tokens: APITokens{"parker-laptop": "abc", "jane-phone": "def"},
+	}, make(chan bool))
+
+	fetch := func(path string, authorize func(req *http.Request)) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorize != nil {
+			authorize(req)
+		}
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, fetch("/feed.atom", nil))
+	assert.Equal(t, http.StatusUnauthorized, fetch("/feed.atom?tok=nope", nil))
+	assert.Equal(t, http.StatusOK, fetch("/feed.atom?tok=abc", nil))
+	assert.Equal(t, http.StatusOK, fetch("/feed.atom?tok=shared", nil))
+	assert.Equal(t, http.StatusOK, fetch("/feed.atom", func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer def")
+	}))
+	assert.Equal(t, http.StatusOK, fetch("/feed.atom", func(req *http.Request) {
+		req.SetBasicAuth("jane", "def")
+	}))
+	assert.Equal(t, http.StatusUnauthorized, fetch("/feed.atom", func(req *http.Request) {
+		req.SetBasicAuth("jane", "abcd")
+	}))
+}
+
+func TestFeedHandler_BlankTokens(t *testing.T) {
+	fetch := func(h *FeedHandler, path string) int {
+		recorder := httptest.NewRecorder()
+		h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder.Code
+	}
+
+	h := NewFeedHandler(&fakeRadarItemsStorageService{}, FeedConfig{
+		Title:  "My Feed",
+		URL:    "http://example.com/feed",
+		Tokens: APITokens{"parker-laptop": "abc", "jane-phone": ""},
+	}, make(chan bool))
+	assert.Equal(t, http.StatusUnauthorized, fetch(h, "/feed.atom"))
+	assert.Equal(t, http.StatusUnauthorized, fetch(h, "/feed.atom?tok="))
+	assert.Equal(t, http.StatusOK, fetch(h, "/feed.atom?tok=abc"))
+
+	h = NewFeedHandler(&fakeRadarItemsStorageService{}, FeedConfig{
+		Title:  "My Feed",
+		URL:    "http://example.com/feed",
+		Tokens: APITokens{"jane-phone": ""},
+	}, make(chan bool))
+	assert.Equal(t, http.StatusUnauthorized, fetch(h, "/feed.atom"))
+}
+
+func TestFeedHandler_DoneFeed(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{
+		newItems: []RadarItem{{URL: "https://byparker.com", Title: "By Parker", AddedAt: time.Now()}},
+		doneItems: []RadarItem{
+			{URL: "https://jvns.ca", Title: "Julia Evans", CheckedAt: time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC)},
+			{URL: "https://wizardzines.com", Title: "Wizard Zines", CheckedAt: time.Now().Add(-2 * doneFeedWindow)},
+		},
+	}
+	h := NewFeedHandler(storage, FeedConfig{Title: "My Feed", URL: "http://example.com/feed", APIKey: "foo"}, make(chan bool))
+
+	req := httptest.NewRequest(http.MethodGet, "/feed/done.atom?tok=foo", nil)
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "Done: My Feed")
+	assert.Contains(t, body, "Julia Evans")
+	assert.Contains(t, body, "<updated>2026-10-17T09:30:00Z</updated>")
+	assert.NotContains(t, body, "Wizard Zines")
+	assert.NotContains(t, body, "By Parker")
+
+	req = httptest.NewRequest(http.MethodGet, "/feed.atom?tok=foo", nil)
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, req)
+	assert.Contains(t, recorder.Body.String(), "By Parker")
+	assert.NotContains(t, recorder.Body.String(), "Julia Evans")
+}
@@ -0,0 +1,632 @@
+package radar
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const fileStoreItemsFilename = "items.jsonl"
+
+// fileStoreSummariesDir is the subdirectory summaries are written to, so they're not mistaken for digests.
+const fileStoreSummariesDir = "summaries"
+
+// fileStoreLockFilename exists while a digest is being generated, so that only one process
+// sharing the directory generates it.
+const fileStoreLockFilename = ".generate.lock"
+
+// fileRadarItemRecord is a single line in the items JSONL file.
+type fileRadarItemRecord struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Source      string    `json:"source,omitempty"`
+	SubmittedBy string    `json:"submitted_by,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	AddedAt     time.Time `json:"added_at"`
+	// Digest is the name of the digest which was current when the item was added.
+	Digest string `json:"digest"`
+	// Checked is set once the item has been checked off via the API.
+	Checked bool `json:"checked,omitempty"`
+	// CheckedAt is when the item was checked off via the API.
+	CheckedAt time.Time `json:"checked_at,omitzero"`
+}
+
+func (r fileRadarItemRecord) radarItem() RadarItem {
+	return RadarItem{
+		ID:          radarItemIDForURL(r.URL),
+		URL:         r.URL,
+		Title:       r.Title,
+		Source:      r.Source,
+		SubmittedBy: r.SubmittedBy,
+		Tags:        r.Tags,
+		Note:        r.Note,
+		AddedAt:     r.AddedAt,
+		CheckedAt:   r.CheckedAt,
+	}
+}
+
+// FileRadarItemsService stores radar items in a directory of plain files, suitable for a
+// git-synced notes repository or an Obsidian vault.
+//
+// Every radar item is appended to items.jsonl. Each digest is rendered as a Markdown file
+// named for the day it was generated, e.g. 2026-10-18.md. Checking off an item in the
+// latest Markdown file removes it from the radar. Items are identified by a hash of their URL.
+type FileRadarItemsService struct {
+	dir string
+
+	// Guards reads and writes of the files in dir.
+	mu sync.Mutex
+}
+
+var _ RadarItemsStorageService = &FileRadarItemsService{}
+
+// NewFileRadarItemsService creates a new FileRadarItemsService which stores files in dir.
+func NewFileRadarItemsService(dir string) (*FileRadarItemsService, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.WithMessagef(err, "error creating radar directory %q", dir)
+	}
+	return &FileRadarItemsService{dir: dir}, nil
+}
+
+// digestNames returns the names of all digests, oldest first. Other Markdown files in the
+// directory, e.g. a README, are ignored.
+//
+// Digest names sort lexicographically: 2026-10-18 < 2026-10-18T093000 < 2026-10-19.
+func (s *FileRadarItemsService) digestNames() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		name := strings.TrimSuffix(filepath.Base(match), ".md")
+		if _, err := digestTime(name); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *FileRadarItemsService) digestPath(name string) string {
+	return filepath.Join(s.dir, name+".md")
+}
+
+func (s *FileRadarItemsService) readDigest(names []string, idx int) (*Digest, error) {
+	path := s.digestPath(names[idx])
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error reading digest %q", path)
+	}
+	title := strings.TrimPrefix(strings.SplitN(string(contents), "\n", 2)[0], "# ")
+	createdAt, err := s.digestCreatedAt(names[idx], path)
+	if err != nil {
+		return nil, err
+	}
+	return &Digest{Number: idx + 1, Title: title, URL: path, Body: string(contents), CreatedAt: createdAt}, nil
+}
+
+// digestCreatedAt returns when the named digest was generated. Names like 2026-10-18 only
+// give the day, so the file's modification time is used if it's on that day. Checking items
+// off later in the day moves it forward, which is close enough.
+func (s *FileRadarItemsService) digestCreatedAt(name, path string) (time.Time, error) {
+	createdAt, err := digestTime(name)
+	if err != nil || strings.Contains(name, "T") {
+		return createdAt, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if modTime := info.ModTime(); !modTime.Before(createdAt) && modTime.Before(createdAt.AddDate(0, 0, 1)) {
+		return modTime, nil
+	}
+	return createdAt, nil
+}
+
+// lockGeneration claims the directory for generating a digest, returning a function which
+// releases it. Locks older than generationLockTTL are assumed to be left over from a crash.
+func (s *FileRadarItemsService) lockGeneration() (func(), error) {
+	path := filepath.Join(s.dir, fileStoreLockFilename)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		if info, statErr := os.Stat(path); statErr != nil || time.Since(info.ModTime()) < generationLockTTL {
+			return nil, ErrDigestGenerationInProgress
+		}
+		Printf("removing stale lock %s", path)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, errors.WithMessage(err, "error removing stale lock")
+		}
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if os.IsExist(err) {
+			return nil, ErrDigestGenerationInProgress
+		}
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "error creating lock")
+	}
+	fmt.Fprintf(f, "pid=%d at=%s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+	f.Close()
+
+	return func() {
+		if err := os.Remove(path); err != nil {
+			Printf("error removing lock %s: %+v", path, err)
+		}
+	}, nil
+}
+
+func (s *FileRadarItemsService) writeDigest(name, title, body string) error {
+	return os.WriteFile(s.digestPath(name), []byte("# "+title+"\n\n"+body), 0644)
+}
+
+// digestTime returns the time a digest was generated, from its name.
+func digestTime(name string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02T150405", name, time.Local); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", name, time.Local)
+}
+
+// newDigestName returns a name for a digest generated now which doesn't clash with an existing one.
+func (s *FileRadarItemsService) newDigestName(now time.Time) string {
+	name := now.Format("2006-01-02")
+	if _, err := os.Stat(s.digestPath(name)); err == nil {
+		name = now.Format("2006-01-02T150405")
+	}
+	return name
+}
+
+// currentDigest returns the latest digest and its name, creating one if none exists.
+func (s *FileRadarItemsService) currentDigest() (*Digest, string, error) {
+	names, err := s.digestNames()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(names) == 0 {
+		name := s.newDigestName(time.Now())
+		if err := s.writeDigest(name, getTitle(time.Now()), welcomeDigestBody+"\n"); err != nil {
+			return nil, "", errors.WithMessage(err, "error creating digest")
+		}
+		names = []string{name}
+	}
+	digest, err := s.readDigest(names, len(names)-1)
+	return digest, names[len(names)-1], err
+}
+
+func (s *FileRadarItemsService) readRecords() ([]fileRadarItemRecord, error) {
+	f, err := os.Open(filepath.Join(s.dir, fileStoreItemsFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []fileRadarItemRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var record fileRadarItemRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, errors.WithMessagef(err, "error parsing %s line %d", fileStoreItemsFilename, len(records)+1)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// writeRecords replaces the items JSONL file with the given records.
+func (s *FileRadarItemsService) writeRecords(records []fileRadarItemRecord) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(s.dir, fileStoreItemsFilename)
+	if err := os.WriteFile(path+".tmp", buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// GetDigest returns the latest Markdown digest, creating one if none exists.
+func (s *FileRadarItemsService) GetDigest(ctx context.Context) (*Digest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	digest, _, err := s.currentDigest()
+	return digest, err
+}
+
+// List returns the unchecked items from the latest digest and the items added since it was generated.
+func (s *FileRadarItemsService) List(ctx context.Context) ([]RadarItem, []RadarItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.list()
+}
+
+func (s *FileRadarItemsService) list() ([]RadarItem, []RadarItem, error) {
+	digest, name, err := s.currentDigest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldItems, err := extractLinkedTodosFromMarkdown(digest.Body)
+	if err != nil {
+		Printf("Error parsing digest %s: %#v", digest.URL, err)
+	}
+	withURLIDs(oldItems)
+
+	records, err := s.readRecords()
+	if err != nil {
+		return nil, nil, err
+	}
+	var newItems []RadarItem
+	for _, record := range records {
+		if record.Digest != name || record.Checked {
+			continue
+		}
+		newItems = append(newItems, record.radarItem())
+	}
+
+	return oldItems, newItems, nil
+}
+
+// Create appends a radar item to the JSONL file.
+func (s *FileRadarItemsService) Create(ctx context.Context, m RadarItem) (*RadarItem, error) {
+	record := fileRadarItemRecord{
+		URL:         m.URL,
+		Title:       m.GetTitle(),
+		Source:      m.Source,
+		SubmittedBy: m.SubmittedBy,
+		Tags:        m.Tags,
+		Note:        m.Note,
+		AddedAt:     m.AddedAt,
+	}
+	if record.AddedAt.IsZero() {
+		record.AddedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldItems, newItems, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDuplicate(m.URL, newItems, oldItems); err != nil {
+		return nil, err
+	}
+
+	_, name, err := s.currentDigest()
+	if err != nil {
+		return nil, err
+	}
+	record.Digest = name
+
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(record); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(s.dir, fileStoreItemsFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error opening items file")
+	}
+	if _, err := f.Write(line.Bytes()); err != nil {
+		f.Close()
+		return nil, errors.WithMessage(err, "error writing items file")
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	item := record.radarItem()
+	return &item, nil
+}
+
+// ListDone returns the items checked off at or after since: those checked via the API, and
+// those checked in a Markdown digest which has since been replaced. Items checked in a digest
+// are given the time the next digest was generated as their CheckedAt.
+func (s *FileRadarItemsService) ListDone(ctx context.Context, since time.Time) ([]RadarItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.listDone(since)
+}
+
+func (s *FileRadarItemsService) listDone(since time.Time) ([]RadarItem, error) {
+	var items []RadarItem
+	names, err := s.digestNames()
+	if err != nil {
+		return nil, err
+	}
+	for idx := 0; idx < len(names)-1; idx++ {
+		retiredAt, err := digestTime(names[idx+1])
+		if err != nil {
+			Printf("Couldn't tell when digest %s was retired: %#v", names[idx], err)
+			continue
+		}
+		if retiredAt.Before(since) {
+			continue
+		}
+		digest, err := s.readDigest(names, idx)
+		if err != nil {
+			return nil, err
+		}
+		checkedItems, err := extractCheckedTodosFromMarkdown(digest.Body)
+		if err != nil {
+			Printf("Error parsing digest %s: %#v", digest.URL, err)
+		}
+		for _, item := range withURLIDs(checkedItems) {
+			item.CheckedAt = retiredAt
+			items = append(items, item)
+		}
+	}
+
+	records, err := s.readRecords()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.Checked && !record.CheckedAt.Before(since) {
+			items = append(items, record.radarItem())
+		}
+	}
+
+	sortDoneRadarItems(items)
+	return items, nil
+}
+
+// Get returns the unchecked item with the given ID.
+func (s *FileRadarItemsService) Get(ctx context.Context, id int64) (*RadarItem, error) {
+	oldItems, newItems, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range append(newItems, oldItems...) {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+	return nil, ErrRadarItemNotFound
+}
+
+// Update changes the title and/or URL of an item in the latest digest or the items file.
+func (s *FileRadarItemsService) Update(ctx context.Context, id int64, m RadarItem) (*RadarItem, error) {
+	updated := &RadarItem{}
+	if err := s.edit(id, updateRadarItemEdit(m, updated)); err != nil {
+		return nil, err
+	}
+	updated.ID = radarItemIDForURL(updated.URL)
+	return updated, nil
+}
+
+// Check checks off an item in the latest digest or the items file.
+func (s *FileRadarItemsService) Check(ctx context.Context, id int64) error {
+	return s.edit(id, checkRadarItemEdit)
+}
+
+// Delete removes an item from the latest digest or the items file.
+func (s *FileRadarItemsService) Delete(ctx context.Context, id int64) error {
+	return s.edit(id, deleteRadarItemEdit)
+}
+
+func (s *FileRadarItemsService) edit(id int64, fn radarItemEdit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	digest, name, err := s.currentDigest()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	if body, ok := editLinkedTodoInMarkdown(digest.Body, id, fn); ok {
+		found = true
+		if err := os.WriteFile(digest.URL, []byte(body), 0644); err != nil {
+			return errors.WithMessage(err, "error writing digest")
+		}
+	}
+
+	records, err := s.readRecords()
+	if err != nil {
+		return err
+	}
+	edited := make([]fileRadarItemRecord, 0, len(records))
+	recordsChanged := false
+	for _, record := range records {
+		if record.Digest != name || record.Checked || radarItemIDForURL(record.URL) != id {
+			edited = append(edited, record)
+			continue
+		}
+		found, recordsChanged = true, true
+		item := record.radarItem()
+		checked, keep := fn(&item)
+		if !keep {
+			continue
+		}
+		record.URL, record.Title, record.Tags, record.Note, record.Checked = item.URL, item.Title, item.Tags, item.Note, checked
+		if checked {
+			record.CheckedAt = time.Now()
+		}
+		edited = append(edited, record)
+	}
+	if recordsChanged {
+		if err := s.writeRecords(edited); err != nil {
+			return errors.WithMessage(err, "error writing items file")
+		}
+	}
+
+	if !found {
+		return ErrRadarItemNotFound
+	}
+	return nil
+}
+
+// GenerateDigest writes a new Markdown digest containing every unchecked item.
+func (s *FileRadarItemsService) GenerateDigest(ctx context.Context, opts DigestOptions) (*Digest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lockGeneration()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	previous, _, err := s.currentDigest()
+	if err != nil {
+		return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+	}
+	if isGeneratedToday(previous.Title, previous.Body, opts) {
+		return nil, ErrDigestAlreadyGenerated
+	}
+	if err := verifyParsable(previous.Body); err != nil {
+		return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+	}
+
+	data := &tmplData{
+		OldIssueURL: filepath.Base(previous.URL),
+		Mention:     opts.Mention,
+		GroupBy:     opts.GroupBy,
+	}
+	data.OldLinks, data.NewLinks, err = s.list()
+	if err != nil {
+		return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+	}
+	data.expire(opts.ExpireAfter, time.Now())
+	sort.Stable(RadarItems(data.NewLinks))
+	sort.Stable(RadarItems(data.OldLinks))
+	sort.Stable(RadarItems(data.ExpiredLinks))
+
+	body, err := generateBody(data)
+	if err != nil {
+		return nil, err
+	}
+	// The items are only recorded in the digests, so make sure none are dropped.
+	if err := verifyCarriedOver(body, append(data.OldLinks, data.NewLinks...)); err != nil {
+		return nil, &DigestGenerationError{GenerationReasonIncomplete, err}
+	}
+
+	now := opts.now()
+	name := s.newDigestName(now)
+	if err := s.writeDigest(name, getTitle(now), body); err != nil {
+		return nil, errors.WithMessage(err, "error writing digest")
+	}
+
+	digest, _, err := s.currentDigest()
+	return digest, err
+}
+
+// GenerateSummary writes a Markdown summary of the period to the summaries directory, e.g.
+// summaries/2026-10.md.
+func (s *FileRadarItemsService) GenerateSummary(ctx context.Context, opts SummaryOptions) (*Digest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	activity, err := s.activity(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	body := generateSummaryBody(activity, opts)
+	path, err := writeSummaryFile(s.dir, opts, body)
+	if err != nil {
+		return nil, err
+	}
+	return &Digest{Title: opts.Title(), URL: path, Body: body}, nil
+}
+
+// writeSummaryFile writes the summary as Markdown to the summaries subdirectory of dir,
+// returning its path.
+func writeSummaryFile(dir string, opts SummaryOptions, body string) (string, error) {
+	dir = filepath.Join(dir, fileStoreSummariesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.WithMessage(err, "error creating summaries directory")
+	}
+	path := filepath.Join(dir, opts.Name()+".md")
+	if err := os.WriteFile(path, []byte("# "+opts.Title()+"\n\n"+body), 0644); err != nil {
+		return "", errors.WithMessage(err, "error writing summary")
+	}
+	return path, nil
+}
+
+// activity reads what happened during the period from the digests and the items file.
+func (s *FileRadarItemsService) activity(opts SummaryOptions) (*radarActivity, error) {
+	activity := &radarActivity{}
+	names, err := s.digestNames()
+	if err != nil {
+		return nil, err
+	}
+	for idx, name := range names {
+		generatedAt, err := digestTime(name)
+		if err != nil || !generatedAt.Before(opts.End) {
+			continue
+		}
+		digest, err := s.readDigest(names, idx)
+		if err != nil {
+			return nil, err
+		}
+		uncheckedItems, err := extractLinkedTodosFromMarkdown(digest.Body)
+		if err != nil {
+			Printf("Error parsing digest %s: %#v", digest.URL, err)
+		}
+		checkedItems, err := extractCheckedTodosFromMarkdown(digest.Body)
+		if err != nil {
+			Printf("Error parsing digest %s: %#v", digest.URL, err)
+		}
+		activity.addAdded(opts, uncheckedItems...)
+		activity.addAdded(opts, checkedItems...)
+		if opts.includes(generatedAt) {
+			// Every todo in the digest was unchecked when it was generated.
+			activity.Backlog = append(activity.Backlog, backlogSize{Date: generatedAt, Size: len(uncheckedItems) + len(checkedItems)})
+			activity.Expired = append(activity.Expired, extractExpiredLinksFromMarkdown(digest.Body)...)
+		}
+	}
+
+	records, err := s.readRecords()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		activity.addAdded(opts, record.radarItem())
+	}
+
+	doneItems, err := s.listDone(opts.Start)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range doneItems {
+		if opts.includes(item.CheckedAt) {
+			activity.Done = append(activity.Done, item)
+		}
+	}
+
+	activity.finish()
+	return activity, nil
+}
+
+// Shutdown is a no-op; every write is flushed immediately.
+func (s *FileRadarItemsService) Shutdown(ctx context.Context) {
+}
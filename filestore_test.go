@@ -0,0 +1,196 @@
+package radar
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRadarItemsService_CreateAndList(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc, err := NewFileRadarItemsService(dir)
+	require.NoError(t, err)
+
+	_, err = svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", Source: SourceEmail, Tags: []string{"zines"}, Note: "So good"})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker"})
+	assert.NoError(t, err)
+
+	oldItems, newItems, err := svc.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, oldItems)
+	if assert.Len(t, newItems, 2) {
+		assert.Equal(t, radarItemIDForURL("https://jvns.ca"), newItems[0].ID)
+		assert.Equal(t, "Julia Evans", newItems[0].Title)
+		assert.Equal(t, SourceEmail, newItems[0].Source)
+		assert.Equal(t, []string{"zines"}, newItems[0].Tags)
+		assert.Equal(t, "So good", newItems[0].Note)
+		assert.False(t, newItems[0].AddedAt.IsZero())
+	}
+
+	contents, err := os.ReadFile(filepath.Join(dir, "items.jsonl"))
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(contents)), "\n"), 2)
+}
+
+func TestFileRadarItemsService_GenerateDigest(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc, err := NewFileRadarItemsService(dir)
+	require.NoError(t, err)
+
+	_, err = svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans"})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker"})
+	assert.NoError(t, err)
+
+	digest, err := svc.GenerateDigest(ctx, DigestOptions{Mention: "@monalisa"})
+	assert.NoError(t, err)
+	assert.Equal(t, getTitle(time.Now()), digest.Title)
+	// The first digest was created for today on the first Create, so this one gets a timestamped name.
+	assert.True(t, strings.HasPrefix(filepath.Base(digest.URL), time.Now().Format("2006-01-02T")), digest.URL)
+	assert.Contains(t, digest.Body, "[ ] [Julia Evans](https://jvns.ca)")
+
+	// Check off an item in the Markdown file, as one would in a text editor.
+	checked := strings.Replace(digest.Body, "[ ] [Julia Evans]", "[x] [Julia Evans]", 1)
+	assert.NoError(t, os.WriteFile(digest.URL, []byte(checked), 0644))
+
+	oldItems, newItems, err := svc.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, newItems)
+	if assert.Len(t, oldItems, 1) {
+		assert.Equal(t, radarItemIDForURL("https://byparker.com"), oldItems[0].ID)
+		assert.Equal(t, "By Parker", oldItems[0].Title)
+		// The add date is carried over in the Markdown.
+		assert.WithinDuration(t, time.Now(), oldItems[0].AddedAt, time.Minute)
+	}
+}
+
+func TestFileRadarItemsService_GenerateDigest_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc, err := NewFileRadarItemsService(dir)
+	require.NoError(t, err)
+
+	// Another process is generating the digest.
+	lockPath := filepath.Join(dir, fileStoreLockFilename)
+	require.NoError(t, os.WriteFile(lockPath, nil, 0644))
+	_, err = svc.GenerateDigest(ctx, DigestOptions{})
+	assert.ErrorIs(t, err, ErrDigestGenerationInProgress)
+
+	// It died without releasing the lock.
+	stale := time.Now().Add(-2 * generationLockTTL)
+	require.NoError(t, os.Chtimes(lockPath, stale, stale))
+	digest, err := svc.GenerateDigest(ctx, DigestOptions{})
+	assert.NoError(t, err)
+	assert.NoFileExists(t, lockPath)
+
+	_, err = svc.GenerateDigest(ctx, DigestOptions{})
+	assert.ErrorIs(t, err, ErrDigestAlreadyGenerated)
+	names, err := svc.digestNames()
+	assert.NoError(t, err)
+	// Just the welcome digest and today's.
+	if assert.Len(t, names, 2) {
+		assert.Equal(t, filepath.Base(digest.URL), names[1]+".md")
+	}
+}
+
+func TestFileRadarItemsService_GenerateDigest_Unparsable(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc, err := NewFileRadarItemsService(dir)
+	require.NoError(t, err)
+	require.NoError(t, svc.writeDigest("2026-10-17", "Radar for 2026-10-17", "## *Previously:*\n\n- [ ] [By Parker](https://byparker.com)\n- [ ] Call mom\n"))
+
+	_, err = svc.GenerateDigest(ctx, DigestOptions{})
+	var generationErr *DigestGenerationError
+	if assert.ErrorAs(t, err, &generationErr) {
+		assert.Equal(t, GenerationReasonReadFailed, generationErr.Reason)
+		assert.ErrorContains(t, err, "Call mom")
+	}
+	names, err := svc.digestNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2026-10-17"}, names, "the digest is left as it was")
+}
+
+func TestFileRadarItemsService_GenerateDigest_OtherMarkdownFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc, err := NewFileRadarItemsService(dir)
+	require.NoError(t, err)
+	require.NoError(t, svc.writeDigest("2026-10-17", "Radar for 2026-10-17", "## *Previously:*\n\n- [ ] [By Parker](https://byparker.com)\n"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# My radar\n\n- [ ] Call mom\n"), 0644))
+
+	names, err := svc.digestNames()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2026-10-17"}, names)
+
+	digest, err := svc.GenerateDigest(ctx, DigestOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, digest.Body, "[By Parker](https://byparker.com)")
+	assert.FileExists(t, filepath.Join(dir, "README.md"))
+}
+
+func TestFileRadarItemsService_ListDone(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc, err := NewFileRadarItemsService(dir)
+	require.NoError(t, err)
+
+	// A digest retired by the next day's, with one item checked off in Markdown.
+	require.NoError(t, svc.writeDigest("2026-10-16", "Radar for 2026-10-16", "## *Previously:*\n\n- [x] [Julia Evans](https://jvns.ca)\n- [ ] [By Parker](https://byparker.com)\n"))
+	require.NoError(t, svc.writeDigest("2026-10-17", "Radar for 2026-10-17", "## *Previously:*\n\n- [ ] [By Parker](https://byparker.com)\n"))
+
+	_, err = svc.Create(ctx, RadarItem{URL: "https://wizardzines.com", Title: "Wizard Zines"})
+	assert.NoError(t, err)
+	assert.NoError(t, svc.Check(ctx, radarItemIDForURL("https://wizardzines.com")))
+
+	doneItems, err := svc.ListDone(ctx, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, doneItems, 2) {
+		assert.Equal(t, "Wizard Zines", doneItems[0].Title)
+		assert.WithinDuration(t, time.Now(), doneItems[0].CheckedAt, time.Minute)
+		assert.Equal(t, "Julia Evans", doneItems[1].Title)
+		assert.Equal(t, radarItemIDForURL("https://jvns.ca"), doneItems[1].ID)
+		assert.Equal(t, time.Date(2026, time.October, 17, 0, 0, 0, 0, time.Local), doneItems[1].CheckedAt)
+	}
+
+	doneItems, err = svc.ListDone(ctx, time.Date(2026, time.October, 18, 0, 0, 0, 0, time.Local))
+	assert.NoError(t, err)
+	if assert.Len(t, doneItems, 1) {
+		assert.Equal(t, "Wizard Zines", doneItems[0].Title)
+	}
+}
+
+func TestFileRadarItemsService_GenerateSummary(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	svc, err := NewFileRadarItemsService(dir)
+	require.NoError(t, err)
+
+	addedAt := time.Date(2026, time.October, 13, 9, 0, 0, 0, time.Local)
+	require.NoError(t, svc.writeDigest("2026-10-13", "Radar for 2026-10-13", "## New:\n\n- [x] [Julia Evans](https://jvns.ca) <!-- added="+addedAt.UTC().Format(time.RFC3339)+" -->\n- [ ] [By Parker](https://byparker.com)\n\n## Expired:\n\n- [xkcd](https://xkcd.com)\n"))
+	require.NoError(t, svc.writeDigest("2026-10-14", "Radar for 2026-10-14", "## *Previously:*\n\n- [ ] [By Parker](https://byparker.com)\n"))
+
+	opts := SummaryOptions{
+		Period: SummaryWeekly,
+		Start:  time.Date(2026, time.October, 12, 0, 0, 0, 0, time.Local),
+		End:    time.Date(2026, time.October, 19, 0, 0, 0, 0, time.Local),
+	}
+	summary, err := svc.GenerateSummary(ctx, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "summaries", "week-of-2026-10-12.md"), summary.URL)
+	assert.Contains(t, summary.Body, "- 1 link added\n- 1 link read\n- 1 link expired\n- Backlog went from 2 to 1 (-1) over 2 digests\n")
+
+	// Summaries aren't digests.
+	digest, err := svc.GetDigest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "Radar for 2026-10-14", digest.Title)
+}
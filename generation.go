@@ -0,0 +1,147 @@
+package radar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/technoweenie/grohl"
+)
+
+// Reasons reported in GenerationStatus and DigestGenerationError.
+const (
+	// GenerationReasonReadFailed means the previous digest couldn't be read in full, so
+	// generating a new one might have lost items.
+	GenerationReasonReadFailed = "read_failed"
+	// GenerationReasonIncomplete means the new digest was missing items from the previous one.
+	GenerationReasonIncomplete = "incomplete"
+	// GenerationReasonAlreadyGenerated means today's digest already existed.
+	GenerationReasonAlreadyGenerated = "already_generated"
+	// GenerationReasonInProgress means another generator held the lock.
+	GenerationReasonInProgress = "in_progress"
+	// GenerationReasonFailed is any other failure.
+	GenerationReasonFailed = "failed"
+)
+
+// DigestGenerationError explains why a digest wasn't generated. The previous digest is left
+// as it was, so no items are lost.
+type DigestGenerationError struct {
+	// Reason is one of the GenerationReason constants.
+	Reason string
+	Err    error
+}
+
+func (e *DigestGenerationError) Error() string {
+	return e.Reason + ": " + e.Err.Error()
+}
+
+func (e *DigestGenerationError) Unwrap() error {
+	return e.Err
+}
+
+// GenerationStatus is the outcome of the latest attempt to generate a digest.
+type GenerationStatus struct {
+	At time.Time
+	// Ok is false if the attempt failed. Finding nothing to do is ok.
+	Ok bool
+	// Reason is one of the GenerationReason constants, or blank if a digest was generated.
+	Reason string `json:",omitempty"`
+	Error  string `json:",omitempty"`
+}
+
+var lastGeneration struct {
+	sync.Mutex
+	status *GenerationStatus
+}
+
+// recordGeneration logs the outcome of an attempt to generate a digest and remembers it for
+// the /health endpoint.
+func recordGeneration(digest *Digest, err error) {
+	status := &GenerationStatus{At: time.Now(), Ok: err == nil}
+	var generationErr *DigestGenerationError
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrDigestAlreadyGenerated):
+		status.Ok, status.Reason = true, GenerationReasonAlreadyGenerated
+	case errors.Is(err, ErrDigestGenerationInProgress):
+		status.Ok, status.Reason = true, GenerationReasonInProgress
+	case errors.As(err, &generationErr):
+		status.Reason = generationErr.Reason
+	default:
+		status.Reason = GenerationReasonFailed
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	data := grohl.Data{"at": "generate_digest", "ok": status.Ok}
+	if status.Reason != "" {
+		data["reason"] = status.Reason
+	}
+	if status.Error != "" {
+		data["error"] = status.Error
+	}
+	if digest != nil {
+		data["digest"] = digest.URL
+	}
+	grohl.Log(data)
+
+	lastGeneration.Lock()
+	defer lastGeneration.Unlock()
+	lastGeneration.status = status
+}
+
+// latestGenerationStatus returns the outcome of the latest attempt to generate a digest, or
+// nil if there hasn't been one since the process started.
+func latestGenerationStatus() *GenerationStatus {
+	lastGeneration.Lock()
+	defer lastGeneration.Unlock()
+	if lastGeneration.status == nil {
+		return nil
+	}
+	status := *lastGeneration.status
+	return &status
+}
+
+// verifyCarriedOver returns an error listing any of the items which aren't unchecked todos in
+// body, compared by canonical URL.
+func verifyCarriedOver(body string, items []RadarItem) error {
+	listed, err := extractLinkedTodosFromMarkdown(body)
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, item := range listed {
+		found[canonicalizeURL(item.URL)] = true
+	}
+	var missing []string
+	for _, item := range items {
+		if !found[canonicalizeURL(item.URL)] {
+			missing = append(missing, item.URL)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s missing from the new digest: %s", pluralize(len(missing), "item"), strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// verifyParsable returns an error listing any unchecked todos in bodies which can't be parsed
+// into radar items. They wouldn't be carried over into the next digest, so they'd be lost
+// when the current one is retired.
+func verifyParsable(bodies ...string) error {
+	var unparsable []string
+	for _, body := range bodies {
+		todos, err := unparsableLinkedTodos(body)
+		if err != nil {
+			return err
+		}
+		unparsable = append(unparsable, todos...)
+	}
+	if len(unparsable) > 0 {
+		return fmt.Errorf("%s couldn't be parsed: %s", pluralize(len(unparsable), "unchecked item"), strings.Join(unparsable, ", "))
+	}
+	return nil
+}
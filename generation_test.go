@@ -0,0 +1,47 @@
+package radar
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordGeneration(t *testing.T) {
+	for _, tc := range []struct {
+		err            error
+		expectedOk     bool
+		expectedReason string
+	}{
+		{nil, true, ""},
+		{ErrDigestAlreadyGenerated, true, GenerationReasonAlreadyGenerated},
+		{ErrDigestGenerationInProgress, true, GenerationReasonInProgress},
+		{&DigestGenerationError{GenerationReasonIncomplete, errors.New("1 item missing")}, false, GenerationReasonIncomplete},
+		{errors.New("boom"), false, GenerationReasonFailed},
+	} {
+		recordGeneration(nil, tc.err)
+		status := latestGenerationStatus()
+		if assert.NotNil(t, status) {
+			assert.Equal(t, tc.expectedOk, status.Ok, tc.err)
+			assert.Equal(t, tc.expectedReason, status.Reason, tc.err)
+			assert.False(t, status.At.IsZero())
+		}
+	}
+
+	resp := newHealthResponse(t.Context())
+	if assert.NotNil(t, resp.LastGeneration) {
+		assert.Equal(t, GenerationReasonFailed, resp.LastGeneration.Reason)
+		assert.Equal(t, "boom", resp.LastGeneration.Error)
+	}
+	assert.Equal(t, false, resp.ToGrohlData()["generation_ok"])
+}
+
+func TestVerifyCarriedOver(t *testing.T) {
+	items := []RadarItem{{URL: "https://jvns.ca"}, {URL: "https://www.byparker.com/?utm_source=rss"}}
+
+	body := "## New:\n\n### jvns.ca (1)\n\n  * [ ] [Julia Evans](https://jvns.ca)\n\n## *Previously:*\n\n  * [ ] [By Parker](https://byparker.com)\n"
+	assert.NoError(t, verifyCarriedOver(body, items))
+
+	body = "## New:\n\n  * [x] [Julia Evans](https://jvns.ca)\n\n## Expired:\n\n  * [By Parker](https://byparker.com)\n"
+	assert.EqualError(t, verifyCarriedOver(body, items), "2 items missing from the new digest: https://jvns.ca, https://www.byparker.com/?utm_source=rss")
+}
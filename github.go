@@ -5,49 +5,110 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v53/github"
 	"github.com/parkr/changelog"
-	"golang.org/x/oauth2"
+	"github.com/pkg/errors"
 )
 
-// Generate and re-use one client per token. Key = token, value = client for token.
-var clients = map[string]*github.Client{}
+// DefaultRadarLabel is the label radar issues are given, and found by, unless another is configured.
+const DefaultRadarLabel = "radar"
 
-var labels = []string{"radar"}
+var summaryLabels = []string{"radar-summary"}
 
 type tmplData struct {
 	OldIssueURL string
 	NewLinks    []RadarItem
 	OldLinks    []RadarItem
 	Mention     string
+	GroupBy     string
+	// ExpiredLinks are listed once, without checkboxes, so they're not carried over.
+	ExpiredLinks []RadarItem
 }
 
-func GenerateRadarIssue(radarItemsService RadarItemsService, mention string) (*github.Issue, error) {
+// expire moves any old or new links added longer ago than expireAfter into ExpiredLinks.
+func (data *tmplData) expire(expireAfter time.Duration, now time.Time) {
+	var expiredOld, expiredNew []RadarItem
+	data.OldLinks, expiredOld = expireRadarItems(data.OldLinks, expireAfter, now)
+	data.NewLinks, expiredNew = expireRadarItems(data.NewLinks, expireAfter, now)
+	data.ExpiredLinks = append(expiredOld, expiredNew...)
+	if len(data.ExpiredLinks) > 0 {
+		Printf("expiring %d radar items older than %s", len(data.ExpiredLinks), expireAfter)
+	}
+}
+
+// GenerateRadarIssue rolls the current radar items into a new digest using the given storage backend.
+func GenerateRadarIssue(radarItemsService RadarItemsStorageService, opts DigestOptions) (*Digest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	digest, err := radarItemsService.GenerateDigest(ctx, opts)
+	recordGeneration(digest, err)
+	return digest, err
+}
+
+func generateGitHubRadarIssue(ctx context.Context, radarItemsService RadarItemsService, opts DigestOptions) (*github.Issue, error) {
 	client := radarItemsService.githubClient
 	owner, name := radarItemsService.owner, radarItemsService.repoName
 	var err error
 
 	data := &tmplData{
-		Mention: mention,
+		Mention: opts.Mention,
+		GroupBy: opts.GroupBy,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
-	previousIssue := getPreviousRadarIssue(ctx, client, owner, name)
+	var comments []*github.IssueComment
+	previousIssue, err := radarItemsService.currentRadarIssue(ctx)
+	if err != nil {
+		// Carrying on would start an empty radar and orphan the real one.
+		return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+	}
 	if previousIssue != nil {
-		data.OldIssueURL = *previousIssue.HTMLURL
-		data.OldLinks, data.NewLinks, err = extractGitHubLinks(ctx, client, owner, name, previousIssue)
+		if isGeneratedToday(previousIssue.GetTitle(), previousIssue.GetBody(), opts) {
+			return nil, ErrDigestAlreadyGenerated
+		}
+
+		unlock, err := lockGitHubRadarIssue(ctx, client, owner, name, previousIssue.GetNumber())
 		if err != nil {
-			Printf("Unable to extract GitHub links from %s/%s#%d", owner, name, *previousIssue.Number)
 			return nil, err
 		}
+		defer unlock()
+
+		// Another generator may have finished between the search and taking the lock.
+		previousIssue, _, err = client.Issues.Get(ctx, owner, name, previousIssue.GetNumber())
+		if err != nil {
+			return nil, &DigestGenerationError{GenerationReasonReadFailed, errors.WithMessage(err, "error refreshing radar issue")}
+		}
+		if previousIssue.GetState() == "closed" {
+			radarItemsService.current.set(0)
+			return nil, ErrDigestAlreadyGenerated
+		}
+
+		data.OldIssueURL = previousIssue.GetHTMLURL()
+		comments, err = listGitHubComments(ctx, client, owner, name, previousIssue.GetNumber())
+		if err != nil {
+			return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+		}
+		bodies := []string{previousIssue.GetBody()}
+		for _, comment := range comments {
+			bodies = append(bodies, comment.GetBody())
+		}
+		if err := verifyParsable(bodies...); err != nil {
+			return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+		}
+		data.OldLinks, data.NewLinks, err = extractGitHubLinksFromComments(previousIssue, comments)
+		if err != nil {
+			Printf("Unable to extract GitHub links from %s/%s#%d", owner, name, previousIssue.GetNumber())
+			return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+		}
 	}
 
+	data.expire(opts.ExpireAfter, time.Now())
 	sort.Stable(RadarItems(data.NewLinks))
 	sort.Stable(RadarItems(data.OldLinks))
+	sort.Stable(RadarItems(data.ExpiredLinks))
 
 	body, err := generateBody(data)
 	if err != nil {
@@ -56,65 +117,197 @@ func GenerateRadarIssue(radarItemsService RadarItemsService, mention string) (*g
 	}
 
 	newIssue, _, err := client.Issues.Create(ctx, owner, name, &github.IssueRequest{
-		Title:  github.String(getTitle()),
+		Title:  github.String(getTitle(opts.now())),
 		Body:   github.String(body),
-		Labels: &labels,
+		Labels: &[]string{radarItemsService.radarLabel()},
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Close old issue.
-	if previousIssue != nil {
-		_, _, err := client.Issues.Edit(
-			ctx, owner, name, *previousIssue.Number, &github.IssueRequest{State: github.String("closed")},
+	// Only close the old issue once the new one is known to hold everything carried over.
+	// Otherwise, close the new one, so the old one stays current.
+	if err := verifyCarriedOver(newIssue.GetBody(), append(data.OldLinks, data.NewLinks...)); err != nil {
+		_, _, closeErr := client.Issues.Edit(
+			ctx, owner, name, newIssue.GetNumber(), &github.IssueRequest{State: github.String("closed")},
 		)
-		if err != nil {
-			Printf("%s/%s: error closing issue number=%d: %#v", owner, name, *previousIssue.Number, err)
+		if closeErr != nil {
+			Printf("%s/%s: error closing incomplete issue number=%d: %#v", owner, name, newIssue.GetNumber(), closeErr)
 		}
+		return nil, &DigestGenerationError{GenerationReasonIncomplete, err}
+	}
+
+	// New items go on the new issue from now on.
+	radarItemsService.current.set(newIssue.GetNumber())
+	if previousIssue == nil {
+		return newIssue, nil
+	}
+
+	// Items may have been added to the old issue since its comments were listed. Move them
+	// over before closing it, or they'd be lost with it.
+	moved := map[int64]bool{}
+	for _, comment := range comments {
+		moved[comment.GetID()] = true
+	}
+	if err := moveGitHubComments(ctx, client, owner, name, previousIssue.GetNumber(), newIssue.GetNumber(), moved); err != nil {
+		radarItemsService.current.set(0)
+		_, _, closeErr := client.Issues.Edit(
+			ctx, owner, name, newIssue.GetNumber(), &github.IssueRequest{State: github.String("closed")},
+		)
+		if closeErr != nil {
+			Printf("%s/%s: error closing incomplete issue number=%d: %#v", owner, name, newIssue.GetNumber(), closeErr)
+		}
+		return nil, &DigestGenerationError{GenerationReasonIncomplete, err}
+	}
+
+	// Close old issue.
+	_, _, err = client.Issues.Edit(
+		ctx, owner, name, previousIssue.GetNumber(), &github.IssueRequest{State: github.String("closed")},
+	)
+	if err != nil {
+		Printf("%s/%s: error closing issue number=%d: %#v", owner, name, previousIssue.GetNumber(), err)
+		return newIssue, nil
+	}
+
+	// Catch any items added by requests which were already in flight when it was closed.
+	if err := moveGitHubComments(ctx, client, owner, name, previousIssue.GetNumber(), newIssue.GetNumber(), moved); err != nil {
+		Printf("%s/%s: error moving late items from issue number=%d to number=%d: %#v", owner, name, previousIssue.GetNumber(), newIssue.GetNumber(), err)
 	}
 
 	return newIssue, nil
 }
 
-func getPreviousRadarIssue(ctx context.Context, client *github.Client, owner, name string) *github.Issue {
-	query := fmt.Sprintf("repo:%s/%s is:open is:issue label:radar", owner, name)
-	opts := &github.SearchOptions{
-		Sort:        "created",
-		Order:       "desc",
-		ListOptions: github.ListOptions{PerPage: 100},
+// moveGitHubComments copies each comment on issue from which lists todos, and isn't in moved,
+// to issue to, as-is. Each copied comment is added to moved.
+func moveGitHubComments(ctx context.Context, client *github.Client, owner, name string, from, to int, moved map[int64]bool) error {
+	comments, err := listGitHubComments(ctx, client, owner, name, from)
+	if err != nil {
+		return err
 	}
-	result, _, err := client.Search.Issues(ctx, query, opts)
+	for _, comment := range comments {
+		if moved[comment.GetID()] || !linkedTodoCommentRegexp.MatchString(comment.GetBody()) {
+			continue
+		}
+		_, _, err := client.Issues.CreateComment(ctx, owner, name, to, &github.IssueComment{
+			Body: github.String(comment.GetBody()),
+		})
+		if err != nil {
+			return errors.WithMessagef(err, "error moving comment %d", comment.GetID())
+		}
+		moved[comment.GetID()] = true
+		Printf("%s/%s: moved comment %d from issue number=%d to number=%d", owner, name, comment.GetID(), from, to)
+	}
+	return nil
+}
+
+// generationLockMarker starts the comment a generator leaves on the current radar issue to claim it.
+const generationLockMarker = "<!-- radar:generating -->"
+
+// lockGitHubRadarIssue claims the radar issue for generation by commenting on it, returning a
+// function which deletes the comment. If another generator commented first, and its comment
+// is younger than generationLockTTL, it returns ErrDigestGenerationInProgress.
+func lockGitHubRadarIssue(ctx context.Context, client *github.Client, owner, name string, number int) (func(), error) {
+	lock, _, err := client.Issues.CreateComment(ctx, owner, name, number, &github.IssueComment{
+		Body: github.String(generationLockMarker + "\nGenerating the next radar..."),
+	})
 	if err != nil {
-		Printf("Error running query '%s': %#v", query, err)
-		return nil
+		return nil, errors.WithMessage(err, "error locking radar issue")
+	}
+	unlock := func() {
+		if _, err := client.Issues.DeleteComment(ctx, owner, name, lock.GetID()); err != nil {
+			Printf("%s/%s: error unlocking issue number=%d: %#v", owner, name, number, err)
+		}
 	}
 
-	if len(result.Issues) == 0 {
-		Printf("No issues for '%s'.", query)
-		return nil
+	since := lock.GetCreatedAt().Add(-generationLockTTL)
+	comments, _, err := client.Issues.ListComments(ctx, owner, name, number, &github.IssueListCommentsOptions{
+		Since:       &since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	})
+	if err != nil {
+		unlock()
+		return nil, errors.WithMessage(err, "error listing radar issue locks")
 	}
 
-	return result.Issues[0]
+	// Comments are listed oldest first, so the first live lock wins.
+	for _, comment := range comments {
+		if !strings.HasPrefix(comment.GetBody(), generationLockMarker) || comment.GetCreatedAt().Before(since) {
+			continue
+		}
+		if comment.GetID() != lock.GetID() {
+			unlock()
+			return nil, ErrDigestGenerationInProgress
+		}
+		break
+	}
+	return unlock, nil
 }
 
-func getTitle() string {
-	return fmt.Sprintf("Radar for %s", time.Now().Format("2006-01-02"))
+// getPreviousRadarIssue returns the latest open issue with the label, or nil if there isn't one.
+func getPreviousRadarIssue(ctx context.Context, client *github.Client, owner, name, label string) (*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      []string{label},
+		Sort:        "created",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 10},
+	}
+	for {
+		issues, resp, err := client.Issues.ListByRepo(ctx, owner, name, opts)
+		if err != nil {
+			Printf("Error listing open issues labeled %q: %#v", label, err)
+			return nil, errors.WithMessage(err, "error listing radar issues")
+		}
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				return issue, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+
+	Printf("No open issues labeled %q in %s/%s.", label, owner, name)
+	return nil, nil
+}
+
+func digestFromGitHubIssue(issue *github.Issue) *Digest {
+	return &Digest{
+		Number:    issue.GetNumber(),
+		Title:     issue.GetTitle(),
+		URL:       issue.GetHTMLURL(),
+		Body:      issue.GetBody(),
+		CreatedAt: issueCreatedAt(issue),
+	}
+}
+
+// issueCreatedAt returns when the issue was created, or now if the API didn't say.
+func issueCreatedAt(issue *github.Issue) time.Time {
+	if issue.CreatedAt == nil {
+		return time.Now()
+	}
+	return issue.GetCreatedAt().Time
+}
+
+// getTitle returns the title of the digest for now's date, e.g. "Radar for 2026-10-18".
+func getTitle(now time.Time) string {
+	return fmt.Sprintf("Radar for %s", now.Format("2006-01-02"))
 }
 
 func generateBody(data *tmplData) (string, error) {
-	if len(data.NewLinks) == 0 && len(data.OldLinks) == 0 {
+	if len(data.NewLinks) == 0 && len(data.OldLinks) == 0 && len(data.ExpiredLinks) == 0 {
 		return "Nothing to do today. Nice work! :sparkles:", nil
 	}
 
 	buf := bytes.NewBufferString("A new day, " + data.Mention + "! Here's what you have saved:\n\n")
 	links := changelog.NewChangelog()
-	for _, newIssue := range data.NewLinks {
-		links.AddLineToVersion("New:", &changelog.ChangeLine{Summary: "[ ] " + newIssue.GetMarkdown()})
-	}
-	previouslyHeader := "*Previously:*"
-	for _, oldIssue := range data.OldLinks {
-		links.AddLineToVersion(previouslyHeader, &changelog.ChangeLine{Summary: "[ ] " + oldIssue.GetMarkdown()})
+	addLinksToSection(links, "New:", data.NewLinks, data.GroupBy)
+	addLinksToSection(links, "*Previously:*", data.OldLinks, data.GroupBy)
+	for _, expired := range data.ExpiredLinks {
+		links.AddLineToVersion("Expired:", &changelog.ChangeLine{Summary: expired.GetTodoMarkdown()})
 	}
 	fmt.Fprint(buf, links.String())
 	if data.OldIssueURL != "" {
@@ -123,34 +316,182 @@ func generateBody(data *tmplData) (string, error) {
 	return buf.String(), nil
 }
 
+// addLinksToSection adds a todo for each item to the section, under a heading for each group if grouped.
+func addLinksToSection(links *changelog.Changelog, section string, items []RadarItem, groupBy string) {
+	if groupBy == GroupByNone {
+		for _, item := range items {
+			links.AddLineToVersion(section, &changelog.ChangeLine{Summary: "[ ] " + item.GetTodoMarkdown()})
+		}
+		return
+	}
+	for _, group := range groupRadarItems(items, groupBy) {
+		for _, item := range group.Items {
+			links.AddLineToSubsection(section, group.Heading(), &changelog.ChangeLine{Summary: "[ ] " + item.GetTodoMarkdown()})
+		}
+	}
+}
+
 func extractGitHubLinks(ctx context.Context, client *github.Client, owner, name string, issue *github.Issue) ([]RadarItem, []RadarItem, error) {
+	comments, err := listGitHubComments(ctx, client, owner, name, *issue.Number)
+	if err != nil {
+		return nil, nil, err
+	}
+	return extractGitHubLinksFromComments(issue, comments)
+}
+
+// extractGitHubLinksFromComments returns the unchecked items in the issue's body and in the
+// given comments on it.
+func extractGitHubLinksFromComments(issue *github.Issue, comments []*github.IssueComment) ([]RadarItem, []RadarItem, error) {
 	var oldItems []RadarItem
 	var newItems []RadarItem
 
 	extractedItems, err := extractLinkedTodosFromMarkdown(issue.GetBody())
 	if err != nil {
 		Printf("Error parsing issue body: %#v", err)
+		return oldItems, newItems, errors.WithMessage(err, "error parsing issue body")
 	}
 	oldItems = append(oldItems, extractedItems...)
 
-	opts := &github.IssueListCommentsOptions{
-		Sort:        github.String("created"),
-		Direction:   github.String("asc"),
-		ListOptions: github.ListOptions{PerPage: 100},
+	for _, comment := range comments {
+		extractedItems, err := extractLinkedTodosFromMarkdown(comment.GetBody())
+		if err != nil {
+			Printf("Error parsing comment body: %#v", err)
+			return oldItems, newItems, errors.WithMessagef(err, "error parsing comment %d", comment.GetID())
+		}
+		for _, item := range extractedItems {
+			if item.AddedAt.IsZero() {
+				item.AddedAt = comment.GetCreatedAt().Time
+			}
+			newItems = append(newItems, item)
+		}
 	}
-	for {
-		comments, resp, err := client.Issues.ListComments(ctx, owner, name, *issue.Number, opts)
+
+	return oldItems, newItems, nil
+}
+
+// extractCheckedGitHubLinks returns the checked-off items in the issue's body and comments.
+func extractCheckedGitHubLinks(ctx context.Context, client *github.Client, owner, name string, issue *github.Issue) ([]RadarItem, error) {
+	items, err := extractCheckedTodosFromMarkdown(issue.GetBody())
+	if err != nil {
+		Printf("Error parsing issue body: %#v", err)
+	}
+
+	comments, err := listGitHubComments(ctx, client, owner, name, issue.GetNumber())
+	if err != nil {
+		return items, err
+	}
+	for _, comment := range comments {
+		extractedItems, err := extractCheckedTodosFromMarkdown(comment.GetBody())
 		if err != nil {
-			Printf("Error fetching comments: %#v", err)
-			return oldItems, newItems, err
+			Printf("Error parsing comment body: %#v", err)
+		}
+		for _, item := range extractedItems {
+			if item.AddedAt.IsZero() {
+				item.AddedAt = comment.GetCreatedAt().Time
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// githubRadarActivity reads what happened during the period from the radar issues which were
+// open during it. Items checked off in an issue are counted as done when the issue was closed.
+func githubRadarActivity(ctx context.Context, client *github.Client, owner, name, label string, opts SummaryOptions) (*radarActivity, error) {
+	issues, err := listClosedRadarIssues(ctx, client, owner, name, label, opts.Start)
+	if err != nil {
+		return nil, err
+	}
+	current, err := getPreviousRadarIssue(ctx, client, owner, name, label)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		issues = append(issues, current)
+	}
+
+	activity := &radarActivity{}
+	for _, issue := range issues {
+		createdAt := issue.GetCreatedAt().Time
+		if !createdAt.Before(opts.End) {
+			continue
 		}
 
+		uncheckedItems, err := extractLinkedTodosFromMarkdown(issue.GetBody())
+		if err != nil {
+			Printf("Error parsing issue body: %#v", err)
+		}
+		checkedItems, err := extractCheckedTodosFromMarkdown(issue.GetBody())
+		if err != nil {
+			Printf("Error parsing issue body: %#v", err)
+		}
+		if opts.includes(createdAt) {
+			// Every todo in the body was unchecked when the issue was created.
+			activity.Backlog = append(activity.Backlog, backlogSize{Date: createdAt, Size: len(uncheckedItems) + len(checkedItems)})
+			activity.Expired = append(activity.Expired, extractExpiredLinksFromMarkdown(issue.GetBody())...)
+		}
+
+		comments, err := listGitHubComments(ctx, client, owner, name, issue.GetNumber())
+		if err != nil {
+			return nil, err
+		}
 		for _, comment := range comments {
-			extractedItems, err := extractLinkedTodosFromMarkdown(comment.GetBody())
+			uncheckedCommentItems, err := extractLinkedTodosFromMarkdown(comment.GetBody())
 			if err != nil {
 				Printf("Error parsing comment body: %#v", err)
 			}
-			newItems = append(newItems, extractedItems...)
+			checkedCommentItems, err := extractCheckedTodosFromMarkdown(comment.GetBody())
+			if err != nil {
+				Printf("Error parsing comment body: %#v", err)
+			}
+			for _, item := range uncheckedCommentItems {
+				if item.AddedAt.IsZero() {
+					item.AddedAt = comment.GetCreatedAt().Time
+				}
+				uncheckedItems = append(uncheckedItems, item)
+			}
+			for _, item := range checkedCommentItems {
+				if item.AddedAt.IsZero() {
+					item.AddedAt = comment.GetCreatedAt().Time
+				}
+				checkedItems = append(checkedItems, item)
+			}
+		}
+
+		activity.addAdded(opts, uncheckedItems...)
+		activity.addAdded(opts, checkedItems...)
+		if closedAt := issue.GetClosedAt().Time; opts.includes(closedAt) {
+			for _, item := range checkedItems {
+				item.CheckedAt = closedAt
+				activity.Done = append(activity.Done, item)
+			}
+		}
+	}
+	activity.finish()
+	return activity, nil
+}
+
+// listClosedRadarIssues fetches every issue with the label closed at or after since.
+func listClosedRadarIssues(ctx context.Context, client *github.Client, owner, name, label string, since time.Time) ([]*github.Issue, error) {
+	var issues []*github.Issue
+	opts := &github.IssueListByRepoOptions{
+		State:  "closed",
+		Labels: []string{label},
+		// Closing an issue updates it, so this only skips issues closed before since.
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		page, resp, err := client.Issues.ListByRepo(ctx, owner, name, opts)
+		if err != nil {
+			Printf("Error listing closed issues: %#v", err)
+			return issues, err
+		}
+		for _, issue := range page {
+			if issue.IsPullRequest() || issue.GetClosedAt().Before(since) {
+				continue
+			}
+			issues = append(issues, issue)
 		}
 
 		if resp.NextPage == 0 {
@@ -158,20 +499,29 @@ func extractGitHubLinks(ctx context.Context, client *github.Client, owner, name
 		}
 		opts.ListOptions.Page = resp.NextPage
 	}
-
-	return oldItems, newItems, nil
+	return issues, nil
 }
 
-// NewGitHubClient generates a new GitHub client with the given static token source.
-func NewGitHubClient(githubToken string) *github.Client {
-	if _, ok := clients[githubToken]; !ok {
-		clients[githubToken] = github.NewClient(oauth2.NewClient(
-			context.TODO(),
-			oauth2.StaticTokenSource(
-				&oauth2.Token{AccessToken: githubToken},
-			),
-		))
+// listGitHubComments fetches every page of comments on the given issue, oldest first.
+func listGitHubComments(ctx context.Context, client *github.Client, owner, name string, number int) ([]*github.IssueComment, error) {
+	var allComments []*github.IssueComment
+	opts := &github.IssueListCommentsOptions{
+		Sort:        github.String("created"),
+		Direction:   github.String("asc"),
+		ListOptions: github.ListOptions{PerPage: 100},
 	}
+	for {
+		comments, resp, err := client.Issues.ListComments(ctx, owner, name, number, opts)
+		if err != nil {
+			Printf("Error fetching comments: %#v", err)
+			return allComments, err
+		}
+		allComments = append(allComments, comments...)
 
-	return clients[githubToken]
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+	return allComments, nil
 }
@@ -0,0 +1,146 @@
+package radar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/google/go-github/v53/github"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+// GitHubAppConfig identifies a GitHub App installation to authenticate as, so radar issues
+// are authored by the app's bot account rather than a person.
+type GitHubAppConfig struct {
+	// AppID is the app's ID, shown on its settings page.
+	AppID int64
+	// InstallationID is the ID of the app's installation on the radar repository's account.
+	InstallationID int64
+	// PrivateKeyPath is the path to one of the app's private keys, in PEM format.
+	PrivateKeyPath string
+}
+
+func (c GitHubAppConfig) String() string {
+	return fmt.Sprintf("app=%d installation=%d", c.AppID, c.InstallationID)
+}
+
+// GitHubAppConfigFromEnv reads a GitHub App configuration from GITHUB_APP_ID,
+// GITHUB_APP_INSTALLATION_ID and GITHUB_APP_PRIVATE_KEY_PATH. It returns nil if
+// GITHUB_APP_ID isn't set.
+func GitHubAppConfigFromEnv() (*GitHubAppConfig, error) {
+	appID := os.Getenv("GITHUB_APP_ID")
+	if appID == "" {
+		return nil, nil
+	}
+
+	config := &GitHubAppConfig{PrivateKeyPath: os.Getenv("GITHUB_APP_PRIVATE_KEY_PATH")}
+	var err error
+	if config.AppID, err = strconv.ParseInt(appID, 10, 64); err != nil {
+		return nil, errors.WithMessagef(err, "invalid GITHUB_APP_ID %q", appID)
+	}
+	installationID := os.Getenv("GITHUB_APP_INSTALLATION_ID")
+	if config.InstallationID, err = strconv.ParseInt(installationID, 10, 64); err != nil {
+		return nil, errors.WithMessagef(err, "invalid GITHUB_APP_INSTALLATION_ID %q", installationID)
+	}
+	if config.PrivateKeyPath == "" {
+		return nil, errors.New("GITHUB_APP_PRIVATE_KEY_PATH not set")
+	}
+	return config, nil
+}
+
+// githubClientCache generates and re-uses one client per set of credentials. It's safe for
+// concurrent use.
+type githubClientCache struct {
+	mu      sync.Mutex
+	clients map[string]*github.Client
+}
+
+var githubClients = &githubClientCache{}
+
+// get returns the client cached under key, calling newClient to create it if there isn't one.
+func (c *githubClientCache) get(key string, newClient func() (*github.Client, error)) (*github.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = map[string]*github.Client{}
+	}
+	c.clients[key] = client
+	return client, nil
+}
+
+// NewGitHubClient generates a new github.com client with the given static token source.
+// Requests which hit a rate limit or fail with a server error are retried.
+func NewGitHubClient(githubToken string) *github.Client {
+	client, _ := NewGitHubEnterpriseClient(githubToken, nil)
+	return client
+}
+
+// NewGitHubEnterpriseClient generates a new client for a GitHub Enterprise Server instance, or
+// for github.com if enterprise is nil, with the given static token source.
+func NewGitHubEnterpriseClient(githubToken string, enterprise *GitHubEnterprise) (*github.Client, error) {
+	key := fmt.Sprintf("token:%s:%s", githubToken, enterprise)
+	return githubClients.get(key, func() (*github.Client, error) {
+		httpClient := oauth2.NewClient(
+			context.TODO(),
+			oauth2.StaticTokenSource(
+				&oauth2.Token{AccessToken: githubToken},
+			),
+		)
+		httpClient.Transport = newGitHubRetryTransport(httpClient.Transport)
+		return enterprise.newClient(httpClient)
+	})
+}
+
+// NewGitHubAppClient generates a new client which authenticates as a GitHub App installation
+// on a GitHub Enterprise Server instance, or on github.com if enterprise is nil. Installation
+// tokens are fetched with the app's private key and refreshed before they expire. Requests
+// which hit a rate limit or fail with a server error are retried.
+func NewGitHubAppClient(config GitHubAppConfig, enterprise *GitHubEnterprise) (*github.Client, error) {
+	key := fmt.Sprintf("app:%d:%d:%s:%s", config.AppID, config.InstallationID, config.PrivateKeyPath, enterprise)
+	return githubClients.get(key, func() (*github.Client, error) {
+		transport, err := ghinstallation.NewKeyFromFile(http.DefaultTransport, config.AppID, config.InstallationID, config.PrivateKeyPath)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "couldn't load GitHub App private key from %q", config.PrivateKeyPath)
+		}
+		client, err := enterprise.newClient(&http.Client{Transport: newGitHubRetryTransport(transport)})
+		if err != nil {
+			return nil, err
+		}
+		// Installation tokens come from the same API.
+		transport.BaseURL = strings.TrimSuffix(client.BaseURL.String(), "/")
+		return client, nil
+	})
+}
+
+// NewGitHubClientFromEnv generates a GitHub client which authenticates as the GitHub App
+// installation configured in the environment (see GitHubAppConfigFromEnv) if there is one,
+// and with GITHUB_ACCESS_TOKEN otherwise. It talks to the GitHub Enterprise Server instance
+// configured in the environment (see GitHubEnterpriseFromEnv), if any.
+func NewGitHubClientFromEnv() (*github.Client, error) {
+	enterprise, err := GitHubEnterpriseFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	config, err := GitHubAppConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		return NewGitHubAppClient(*config, enterprise)
+	}
+	return NewGitHubEnterpriseClient(os.Getenv("GITHUB_ACCESS_TOKEN"), enterprise)
+}
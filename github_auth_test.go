@@ -0,0 +1,83 @@
+package radar
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestPrivateKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(path, keyPEM, 0600))
+	return path
+}
+
+func TestGitHubAppConfigFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_APP_ID", "")
+	config, err := GitHubAppConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	t.Setenv("GITHUB_APP_ID", "123")
+	t.Setenv("GITHUB_APP_INSTALLATION_ID", "456")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY_PATH", "/etc/radar/app.pem")
+	config, err = GitHubAppConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, &GitHubAppConfig{AppID: 123, InstallationID: 456, PrivateKeyPath: "/etc/radar/app.pem"}, config)
+
+	t.Setenv("GITHUB_APP_INSTALLATION_ID", "")
+	_, err = GitHubAppConfigFromEnv()
+	assert.ErrorContains(t, err, "GITHUB_APP_INSTALLATION_ID")
+
+	t.Setenv("GITHUB_APP_INSTALLATION_ID", "456")
+	t.Setenv("GITHUB_APP_PRIVATE_KEY_PATH", "")
+	_, err = GitHubAppConfigFromEnv()
+	assert.ErrorContains(t, err, "GITHUB_APP_PRIVATE_KEY_PATH")
+}
+
+func TestNewGitHubAppClient(t *testing.T) {
+	config := GitHubAppConfig{AppID: 123, InstallationID: 456, PrivateKeyPath: writeTestPrivateKey(t)}
+
+	client, err := NewGitHubAppClient(config, nil)
+	require.NoError(t, err)
+	require.NotNil(t, client)
+	_, ok := client.Client().Transport.(*githubRetryTransport)
+	assert.True(t, ok, "requests should be retried")
+
+	again, err := NewGitHubAppClient(config, nil)
+	require.NoError(t, err)
+	assert.Same(t, client, again)
+
+	_, err = NewGitHubAppClient(GitHubAppConfig{AppID: 123, InstallationID: 456, PrivateKeyPath: filepath.Join(t.TempDir(), "missing.pem")}, nil)
+	assert.ErrorContains(t, err, "couldn't load GitHub App private key")
+}
+
+func TestNewGitHubClient_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	clients := make([]*github.Client, 20)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i] = NewGitHubClient("concurrent-token")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		assert.Same(t, clients[0], client)
+	}
+	assert.NotSame(t, clients[0], NewGitHubClient("another-token"))
+}
@@ -0,0 +1,103 @@
+package radar
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/pkg/errors"
+)
+
+// GitHubEnterprise is a GitHub Enterprise Server instance to use instead of github.com.
+type GitHubEnterprise struct {
+	// BaseURL is the instance's URL, e.g. "https://github.example.com/". The API is found
+	// under "api/v3/", which is added if it's missing.
+	BaseURL string
+	// UploadURL is where uploads go, under "api/uploads/". It defaults to BaseURL.
+	UploadURL string
+}
+
+// GitHubEnterpriseFromEnv reads a GitHub Enterprise Server configuration from GITHUB_BASE_URL
+// and GITHUB_UPLOAD_URL. It returns nil if GITHUB_BASE_URL isn't set, i.e. to use github.com.
+func GitHubEnterpriseFromEnv() (*GitHubEnterprise, error) {
+	enterprise := &GitHubEnterprise{
+		BaseURL:   os.Getenv("GITHUB_BASE_URL"),
+		UploadURL: os.Getenv("GITHUB_UPLOAD_URL"),
+	}
+	if enterprise.BaseURL == "" {
+		if enterprise.UploadURL != "" {
+			return nil, errors.New("GITHUB_UPLOAD_URL is set but GITHUB_BASE_URL isn't")
+		}
+		return nil, nil
+	}
+	if err := validateGitHubEnterpriseURL(enterprise.BaseURL); err != nil {
+		return nil, errors.WithMessage(err, "invalid GITHUB_BASE_URL")
+	}
+	if enterprise.UploadURL != "" {
+		if err := validateGitHubEnterpriseURL(enterprise.UploadURL); err != nil {
+			return nil, errors.WithMessage(err, "invalid GITHUB_UPLOAD_URL")
+		}
+	}
+	return enterprise, nil
+}
+
+func validateGitHubEnterpriseURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("%q must be an absolute http or https URL, e.g. https://github.example.com/", rawURL)
+	}
+	return nil
+}
+
+// Hostname returns the instance's hostname, e.g. "github.example.com", which its web pages
+// are served from.
+func (e *GitHubEnterprise) Hostname() string {
+	u, err := url.Parse(e.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Hostname(), "api.")
+}
+
+// String identifies the GitHub instance, for cache keys and logs.
+func (e *GitHubEnterprise) String() string {
+	if e == nil {
+		return "github.com"
+	}
+	return e.BaseURL + " " + e.UploadURL
+}
+
+// newClient returns a GitHub client which sends requests with httpClient to the instance, or
+// to github.com if e is nil.
+func (e *GitHubEnterprise) newClient(httpClient *http.Client) (*github.Client, error) {
+	if e == nil {
+		return github.NewClient(httpClient), nil
+	}
+	uploadURL := e.UploadURL
+	if uploadURL == "" {
+		uploadURL = e.BaseURL
+	}
+	client, err := github.NewEnterpriseClient(e.BaseURL, uploadURL, httpClient)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "invalid GitHub Enterprise URL %q", e.BaseURL)
+	}
+	return client, nil
+}
+
+// isGitHubHost returns true if hostname serves GitHub repositories, issues and pull requests
+// which can be looked up with the API: github.com, or the configured GitHub Enterprise Server.
+func isGitHubHost(hostname string) bool {
+	if strings.EqualFold(hostname, "github.com") {
+		return true
+	}
+	enterprise, err := GitHubEnterpriseFromEnv()
+	if err != nil || enterprise == nil {
+		return false
+	}
+	return strings.EqualFold(hostname, enterprise.Hostname())
+}
@@ -0,0 +1,112 @@
+package radar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubEnterpriseFromEnv(t *testing.T) {
+	t.Setenv("GITHUB_BASE_URL", "")
+	t.Setenv("GITHUB_UPLOAD_URL", "")
+	enterprise, err := GitHubEnterpriseFromEnv()
+	assert.NoError(t, err)
+	assert.Nil(t, enterprise)
+
+	t.Setenv("GITHUB_BASE_URL", "https://github.example.com/")
+	enterprise, err = GitHubEnterpriseFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, &GitHubEnterprise{BaseURL: "https://github.example.com/"}, enterprise)
+	assert.Equal(t, "github.example.com", enterprise.Hostname())
+
+	t.Setenv("GITHUB_UPLOAD_URL", "https://uploads.github.example.com/")
+	enterprise, err = GitHubEnterpriseFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "https://uploads.github.example.com/", enterprise.UploadURL)
+
+	t.Setenv("GITHUB_BASE_URL", "github.example.com")
+	_, err = GitHubEnterpriseFromEnv()
+	assert.ErrorContains(t, err, "invalid GITHUB_BASE_URL")
+
+	t.Setenv("GITHUB_BASE_URL", "")
+	_, err = GitHubEnterpriseFromEnv()
+	assert.ErrorContains(t, err, "GITHUB_UPLOAD_URL is set")
+}
+
+func TestNewGitHubEnterpriseClient(t *testing.T) {
+	client, err := NewGitHubEnterpriseClient("enterprise-token", &GitHubEnterprise{BaseURL: "https://github.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+	assert.Equal(t, "https://github.example.com/api/uploads/", client.UploadURL.String())
+
+	client, err = NewGitHubEnterpriseClient("enterprise-token", &GitHubEnterprise{
+		BaseURL:   "https://github.example.com/api/v3/",
+		UploadURL: "https://uploads.github.example.com/",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3/", client.BaseURL.String())
+	assert.Equal(t, "https://uploads.github.example.com/api/uploads/", client.UploadURL.String())
+
+	assert.Equal(t, "https://api.github.com/", NewGitHubClient("enterprise-token").BaseURL.String())
+}
+
+func TestNewGitHubAppClient_Enterprise(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/app/installations/456/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Contains(t, r.Header.Get("Authorization"), "Bearer ")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":      "installation-token",
+			"expires_at": time.Now().Add(time.Hour),
+		})
+	})
+	mux.HandleFunc("/api/v3/repos/parkr/radar/issues/1", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token installation-token", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 1, "title": "Radar"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	config := GitHubAppConfig{AppID: 123, InstallationID: 456, PrivateKeyPath: writeTestPrivateKey(t)}
+	client, err := NewGitHubAppClient(config, &GitHubEnterprise{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	issue, _, err := client.Issues.Get(context.Background(), "parkr", "radar", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Radar", issue.GetTitle())
+}
+
+func Test_titleForWebpage_Enterprise(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/parkr/radar/issues/1", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 1, "title": "Add a summary"})
+	})
+	mux.HandleFunc("/api/v3/repos/parkr/radar/pulls/2", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 2, "title": "Summarize the week"})
+	})
+	mux.HandleFunc("/api/v3/repos/parkr/radar", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "radar", "description": "Links to read"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("GITHUB_BASE_URL", server.URL)
+	t.Setenv("GITHUB_UPLOAD_URL", "")
+	t.Setenv("GITHUB_APP_ID", "")
+	t.Setenv("GITHUB_ACCESS_TOKEN", "enterprise-title-token")
+
+	assert.True(t, isGitHubHost("127.0.0.1"))
+	assert.True(t, isGitHubHost("github.com"))
+	assert.False(t, isGitHubHost("example.com"))
+
+	assert.Equal(t, "Add a summary - Issue #1 - parkr/radar", titleForWebpage(server.URL+"/parkr/radar/issues/1"))
+	assert.Equal(t, "Summarize the week - Pull request #2 - parkr/radar", titleForWebpage(server.URL+"/parkr/radar/pull/2/files"))
+	assert.Equal(t, "parkr/radar: Links to read", titleForWebpage(server.URL+"/parkr/radar"))
+}
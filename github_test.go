@@ -3,22 +3,70 @@ package radar
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/google/go-github/v53/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 )
 
+// testLockComment is the comment the test server creates when the radar issue is locked.
+var testLockComment = &github.IssueComment{
+	ID:        github.Int64(42),
+	Body:      github.String(generationLockMarker + "\nGenerating the next radar..."),
+	CreatedAt: &github.Timestamp{Time: time.Now()},
+}
+
+// handleOpenRadarIssue serves issue as the only open radar issue in repo, e.g. "parkr-test/radar-test",
+// both when open issues are listed and when it's fetched by number.
+func handleOpenRadarIssue(t *testing.T, mux *http.ServeMux, repo string, issue *github.Issue) {
+	issue.State = github.String("open")
+	mux.HandleFunc("/repos/"+repo+"/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "open", r.FormValue("state"))
+		json.NewEncoder(w).Encode([]*github.Issue{issue})
+	})
+	mux.HandleFunc(fmt.Sprintf("/repos/%s/issues/%d", repo, issue.GetNumber()), func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(issue)
+	})
+}
+
 func newTestGitHubClientAndServer(t *testing.T) (*github.Client, *httptest.Server, error) {
 	handler := http.NewServeMux()
 	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 
+		if r.Method == http.MethodPost && r.URL.Path == `/api/v3/repos/parkr-test/radar-test/issues/1887/comments` {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(testLockComment)
+			return
+		}
+		if r.URL.Path == `/api/v3/repos/parkr-test/radar-test/issues/1887/comments` && r.FormValue("since") != "" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode([]*github.IssueComment{testLockComment})
+			return
+		}
+		if r.Method == http.MethodDelete && r.URL.Path == `/api/v3/repos/parkr-test/radar-test/issues/comments/42` {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Method == http.MethodGet && r.URL.Path == `/api/v3/repos/parkr-test/radar-test/issues/1887` {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&github.Issue{
+				Body:    github.String(testData.newStyleBody),
+				HTMLURL: github.String("https://github.com/parkr-test/radar-test/issues/1887"),
+				Number:  github.Int(1887),
+				State:   github.String("open"),
+			})
+			return
+		}
 		if r.URL.Path == `/api/v3/repos/parkr-test/radar-test/issues/1887/comments` && r.FormValue("page") == "" {
 			w.Header().Set("Content-Type", "application/json")
 			w.Header().Set("Link", `<https://api.github.com/api/v3/repos/parkr-test/radar-test/issues/1887/comments?page=2>; rel="next", <https://api.github.com/api/v3/repos/parkr-test/radar-test/issues/1887/comments?page=2>; rel="last", <https://api.github.com/api/v3/repos/parkr-test/radar-test/issues/1887/comments?page=1>; rel="first"`)
@@ -35,17 +83,14 @@ func newTestGitHubClientAndServer(t *testing.T) (*github.Client, *httptest.Serve
 			})
 			return
 		}
-		if r.URL.Path == `/api/v3/search/issues` && r.FormValue("q") == `repo:parkr-test/radar-test is:open is:issue label:radar` {
+		if r.Method == http.MethodGet && r.URL.Path == `/api/v3/repos/parkr-test/radar-test/issues` && r.FormValue("state") == "open" && r.FormValue("labels") == "radar" {
 			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(&github.IssuesSearchResult{
-				Total:             github.Int(1),
-				IncompleteResults: github.Bool(false),
-				Issues: []*github.Issue{
-					{
-						Body:    github.String(testData.newStyleBody),
-						HTMLURL: github.String("https://github.com/parkr-test/radar-test/issues/1887"),
-						Number:  github.Int(1887),
-					},
+			json.NewEncoder(w).Encode([]*github.Issue{
+				{
+					Body:    github.String(testData.newStyleBody),
+					HTMLURL: github.String("https://github.com/parkr-test/radar-test/issues/1887"),
+					Number:  github.Int(1887),
+					State:   github.String("open"),
 				},
 			})
 			return
@@ -57,7 +102,7 @@ func newTestGitHubClientAndServer(t *testing.T) (*github.Client, *httptest.Serve
 			assert.Equal(t, `Radar for `+time.Now().Format("2006-01-02"), issueCreatePayload.GetTitle())
 
 			w.Header().Set("Content-Type", "application/json")
-			issueCreateResponse := &github.Issue{}
+			issueCreateResponse := &github.Issue{Number: github.Int(1888), Title: issueCreatePayload.Title, Body: issueCreatePayload.Body}
 			assert.NoError(t, json.NewEncoder(w).Encode(issueCreateResponse))
 			return
 		}
@@ -155,6 +200,437 @@ func TestGenerateRadarIssue(t *testing.T) {
 	assert.NoError(t, err)
 	service := RadarItemsService{githubClient: client, owner: "parkr-test", repoName: "radar-test"}
 
-	_, err = GenerateRadarIssue(service, "@monalisa")
+	_, err = GenerateRadarIssue(service, DigestOptions{Mention: "@monalisa"})
+	assert.NoError(t, err)
+}
+
+func TestGenerateRadarIssue_AlreadyGenerated(t *testing.T) {
+	mux := http.NewServeMux()
+	handleOpenRadarIssue(t, mux, "parkr-test/radar-test", &github.Issue{
+		Number: github.Int(1887),
+		Title:  github.String(getTitle(time.Now())),
+		Body:   github.String("A new day, @monalisa! Here's what you have saved:"),
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+	_, err := GenerateRadarIssue(service, DigestOptions{Mention: "@monalisa"})
+	assert.ErrorIs(t, err, ErrDigestAlreadyGenerated)
+}
+
+func TestGenerateRadarIssue_Locked(t *testing.T) {
+	now := time.Now()
+	ours := &github.IssueComment{ID: github.Int64(2), Body: github.String(generationLockMarker), CreatedAt: &github.Timestamp{Time: now}}
+	for _, tc := range []struct {
+		name        string
+		otherLockAt time.Time
+		expectedErr error
+	}{
+		{"held by another generator", now.Add(-time.Minute), ErrDigestGenerationInProgress},
+		{"stale", now.Add(-time.Hour), nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			deleted := false
+			created := false
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887", func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1887), State: github.String("open")})
+			})
+			mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887/comments", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					json.NewEncoder(w).Encode(ours)
+					return
+				}
+				json.NewEncoder(w).Encode([]*github.IssueComment{
+					{ID: github.Int64(1), Body: github.String(generationLockMarker), CreatedAt: &github.Timestamp{Time: tc.otherLockAt}},
+					ours,
+				})
+			})
+			mux.HandleFunc("/repos/parkr-test/radar-test/issues/comments/2", func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				deleted = true
+				w.WriteHeader(http.StatusNoContent)
+			})
+			mux.HandleFunc("/repos/parkr-test/radar-test/issues", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					json.NewEncoder(w).Encode([]*github.Issue{{Number: github.Int(1887), State: github.String("open")}})
+					return
+				}
+				created = true
+				json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1888)})
+			})
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{}`))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(server.URL + "/")
+			service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+			_, err := GenerateRadarIssue(service, DigestOptions{Mention: "@monalisa"})
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedErr == nil, created, "created a new issue")
+			assert.True(t, deleted, "removed the lock comment")
+		})
+	}
+}
+
+func TestGenerateRadarIssue_ReadFailed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		http.Error(w, `{"message": "Service Unavailable"}`, http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+	_, err := GenerateRadarIssue(service, DigestOptions{})
+	var generationErr *DigestGenerationError
+	if assert.ErrorAs(t, err, &generationErr) {
+		assert.Equal(t, GenerationReasonReadFailed, generationErr.Reason)
+	}
+	if status := latestGenerationStatus(); assert.NotNil(t, status) {
+		assert.False(t, status.Ok)
+		assert.Equal(t, GenerationReasonReadFailed, status.Reason)
+	}
+}
+
+func TestGenerateRadarIssue_Incomplete(t *testing.T) {
+	var closed []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			closed = append(closed, "1887")
+		}
+		json.NewEncoder(w).Encode(&github.Issue{
+			Number: github.Int(1887),
+			State:  github.String("open"),
+			Body:   github.String("## *Previously:*\n\n- [ ] [Julia Evans](https://jvns.ca)\n- [ ] [By Parker](https://byparker.com)\n"),
+		})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1888", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		closed = append(closed, "1888")
+		json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1888)})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]*github.Issue{{Number: github.Int(1887), State: github.String("open")}})
+			return
+		}
+		// As if the body were truncated.
+		json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1888), Body: github.String("## *Previously:*\n\n- [ ] [Julia Evans](https://jvns.ca)\n")})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			json.NewEncoder(w).Encode(&github.IssueComment{ID: github.Int64(1), Body: github.String(generationLockMarker)})
+			return
+		}
+		json.NewEncoder(w).Encode([]*github.IssueComment{})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/comments/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+	_, err := GenerateRadarIssue(service, DigestOptions{})
+	var generationErr *DigestGenerationError
+	if assert.ErrorAs(t, err, &generationErr) {
+		assert.Equal(t, GenerationReasonIncomplete, generationErr.Reason)
+		assert.ErrorContains(t, err, "1 item missing from the new digest: https://byparker.com")
+	}
+	assert.Equal(t, []string{"1888"}, closed, "only the incomplete issue is closed")
+}
+
+func TestGenerateRadarIssue_Unparsable(t *testing.T) {
+	created := false
+	mux := http.NewServeMux()
+	handleOpenRadarIssue(t, mux, "parkr-test/radar-test", &github.Issue{
+		Number: github.Int(1887),
+		Body:   github.String("## *Previously:*\n\n- [ ] [Julia Evans](https://jvns.ca)\n"),
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			created = true
+			json.NewEncoder(w).Encode(&github.IssueComment{ID: github.Int64(1), Body: github.String(generationLockMarker)})
+			return
+		}
+		json.NewEncoder(w).Encode([]*github.IssueComment{
+			{ID: github.Int64(2), Body: github.String("- [ ] Not a link at all")},
+		})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/comments/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+	_, err := GenerateRadarIssue(service, DigestOptions{})
+	var generationErr *DigestGenerationError
+	if assert.ErrorAs(t, err, &generationErr) {
+		assert.Equal(t, GenerationReasonReadFailed, generationErr.Reason)
+		assert.ErrorContains(t, err, "1 unchecked item couldn't be parsed: Not a link at all")
+	}
+	assert.True(t, created, "took the lock")
+}
+
+func TestGenerateRadarIssue_MovesLateComments(t *testing.T) {
+	commentsListed := 0
+	var moved []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode([]*github.Issue{{Number: github.Int(1887), State: github.String("open")}})
+			return
+		}
+		request := &github.IssueRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(request))
+		json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1888), Body: request.Body})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			assert.Len(t, moved, 1, "moved the first late comment before closing the old issue")
+		}
+		json.NewEncoder(w).Encode(&github.Issue{
+			Number: github.Int(1887),
+			State:  github.String("open"),
+			Body:   github.String("## *Previously:*\n\n- [ ] [Julia Evans](https://jvns.ca)\n"),
+		})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			json.NewEncoder(w).Encode(&github.IssueComment{ID: github.Int64(1), Body: github.String(generationLockMarker)})
+			return
+		}
+		comments := []*github.IssueComment{
+			{ID: github.Int64(1), Body: github.String(generationLockMarker)},
+			{ID: github.Int64(2), Body: github.String("- [ ] [By Parker](https://byparker.com)")},
+		}
+		if r.FormValue("since") == "" {
+			commentsListed++
+		}
+		// Added after generation listed the comments: once before the old issue is closed,
+		// and once while it's being closed.
+		if commentsListed > 1 {
+			comments = append(comments, &github.IssueComment{ID: github.Int64(3), Body: github.String("- [ ] [Ben Balter](https://ben.balter.com)")})
+		}
+		if commentsListed > 2 {
+			comments = append(comments, &github.IssueComment{ID: github.Int64(4), Body: github.String("- [ ] [Wizard Zines](https://wizardzines.com)")})
+		}
+		json.NewEncoder(w).Encode(comments)
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/comments/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1888/comments", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		comment := &github.IssueComment{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(comment))
+		moved = append(moved, comment.GetBody())
+		json.NewEncoder(w).Encode(comment)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+	digest, err := GenerateRadarIssue(service, DigestOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, digest.Body, "https://byparker.com")
+	assert.Equal(t, []string{
+		"- [ ] [Ben Balter](https://ben.balter.com)",
+		"- [ ] [Wizard Zines](https://wizardzines.com)",
+	}, moved)
+}
+
+func TestRadarItemsService_GetDigest_CachesIssue(t *testing.T) {
+	listed, fetched := 0, 0
+	var current *github.Issue
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "reading-list", r.FormValue("labels"))
+		listed++
+		json.NewEncoder(w).Encode([]*github.Issue{
+			{Number: github.Int(1890), PullRequestLinks: &github.PullRequestLinks{}},
+			{Number: github.Int(1888), State: github.String("open")},
+		})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1888", func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		json.NewEncoder(w).Encode(current)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "reading-list")
+	ctx := context.Background()
+
+	// Pull requests are skipped.
+	digest, err := service.GetDigest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1888, digest.Number)
+	assert.Equal(t, 1, listed)
+
+	// The issue is fetched by number once it's known.
+	current = &github.Issue{Number: github.Int(1888), State: github.String("open")}
+	digest, err = service.GetDigest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1888, digest.Number)
+	assert.Equal(t, 1, listed)
+	assert.Equal(t, 1, fetched)
+
+	// Once it's closed, e.g. by another process, it's looked up again.
+	current = &github.Issue{Number: github.Int(1888), State: github.String("closed")}
+	_, err = service.GetDigest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, listed)
+	assert.Equal(t, 2, fetched)
+}
+
+func TestGenerateRadarIssue_FakeStorage(t *testing.T) {
+	storage := &fakeRadarItemsStorageService{newItems: []RadarItem{{URL: "https://jvns.ca", Title: "Julia Evans"}}}
+
+	digest, err := GenerateRadarIssue(storage, DigestOptions{Mention: "@monalisa"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, digest.Number)
+	assert.Equal(t, getTitle(time.Now()), digest.Title)
+	assert.Len(t, storage.oldItems, 1)
+	assert.Empty(t, storage.newItems)
+}
+
+func TestRadarItemsService_ListDone(t *testing.T) {
+	closedAt := time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "closed", r.FormValue("state"))
+		assert.Equal(t, "radar", r.FormValue("labels"))
+		json.NewEncoder(w).Encode([]*github.Issue{
+			{
+				Number:   github.Int(1887),
+				Body:     github.String("## *Previously:*\n\n- [x] [Julia Evans](https://jvns.ca)\n- [ ] [By Parker](https://byparker.com)\n"),
+				ClosedAt: &github.Timestamp{Time: closedAt},
+			},
+			{
+				Number:   github.Int(1886),
+				Body:     github.String("- [x] [Old News](https://example.com)\n"),
+				ClosedAt: &github.Timestamp{Time: closedAt.Add(-30 * 24 * time.Hour)},
+			},
+		})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1887/comments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*github.IssueComment{
+			{Body: github.String("- [x] [Wizard Zines](https://wizardzines.com)"), CreatedAt: &github.Timestamp{Time: closedAt.Add(-time.Hour)}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+	items, err := service.ListDone(context.Background(), closedAt.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, "Julia Evans", items[0].Title)
+		assert.Equal(t, closedAt, items[0].CheckedAt)
+		assert.Equal(t, radarItemIDForURL("https://jvns.ca"), items[0].ID)
+		assert.Equal(t, "Wizard Zines", items[1].Title)
+		assert.Equal(t, closedAt.Add(-time.Hour), items[1].AddedAt)
+	}
+}
+
+func TestRadarItemsService_GenerateSummary(t *testing.T) {
+	opts := SummaryOptions{
+		Period: SummaryWeekly,
+		Start:  time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC),
+		End:    time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+	}
+	addedAt := opts.Start.Add(2 * time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			issueRequest := &github.IssueRequest{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(issueRequest))
+			assert.Equal(t, "Radar summary for the week of 2026-10-12", issueRequest.GetTitle())
+			assert.Equal(t, []string{"radar-summary"}, issueRequest.GetLabels())
+			json.NewEncoder(w).Encode(&github.Issue{Number: github.Int(1890), Title: issueRequest.Title, Body: issueRequest.Body})
+			return
+		}
+		if r.FormValue("state") == "open" {
+			json.NewEncoder(w).Encode([]*github.Issue{{
+				Number:    github.Int(1889),
+				Body:      github.String("## *Previously:*\n\n- [ ] [By Parker](https://byparker.com)\n"),
+				CreatedAt: &github.Timestamp{Time: opts.Start.Add(25 * time.Hour)},
+			}})
+			return
+		}
+		json.NewEncoder(w).Encode([]*github.Issue{{
+			Number:    github.Int(1888),
+			Body:      github.String("## New:\n\n- [x] [Julia Evans](https://jvns.ca)\n- [ ] [By Parker](https://byparker.com)\n\n## Expired:\n\n- [xkcd](https://xkcd.com)\n"),
+			CreatedAt: &github.Timestamp{Time: opts.Start.Add(time.Hour)},
+			ClosedAt:  &github.Timestamp{Time: opts.Start.Add(25 * time.Hour)},
+		}})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1888/comments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*github.IssueComment{
+			{Body: github.String("- [x] [Wizard Zines](https://wizardzines.com)"), CreatedAt: &github.Timestamp{Time: addedAt}},
+		})
+	})
+	mux.HandleFunc("/repos/parkr-test/radar-test/issues/1889/comments", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]*github.IssueComment{
+			{Body: github.String("- [ ] [Rust](https://rust-lang.org)"), CreatedAt: &github.Timestamp{Time: addedAt.Add(24 * time.Hour)}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	service := NewRadarItemsService(client, "parkr-test", "radar-test", "")
+
+	summary, err := service.GenerateSummary(context.Background(), opts)
 	assert.NoError(t, err)
+	assert.Equal(t, 1890, summary.Number)
+	assert.Contains(t, summary.Body, "- 2 links added\n- 2 links read\n- 1 link expired\n- Backlog went from 2 to 1 (-1) over 2 digests\n")
+	assert.Contains(t, summary.Body, "## Read:\n\n  * [Julia Evans](https://jvns.ca)\n  * [Wizard Zines](https://wizardzines.com)\n")
 }
@@ -0,0 +1,100 @@
+package radar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Values for DigestOptions.GroupBy.
+const (
+	GroupByNone = ""
+	GroupByHost = "host"
+	GroupByTag  = "tag"
+	GroupByWeek = "week"
+)
+
+// ungroupedName is the group for items which have no value for the grouping, e.g. no tags.
+const ungroupedName = "Other"
+
+// radarItemGroupers return the name of the group an item belongs in, for each kind of grouping.
+var radarItemGroupers = map[string]func(item RadarItem) string{
+	GroupByHost: func(item RadarItem) string {
+		return strings.TrimPrefix(strings.ToLower(item.GetHostname()), "www.")
+	},
+	GroupByTag: func(item RadarItem) string {
+		// Items are listed once, under their first tag.
+		if len(item.Tags) == 0 {
+			return ""
+		}
+		return "#" + item.Tags[0]
+	},
+	GroupByWeek: func(item RadarItem) string {
+		if item.AddedAt.IsZero() {
+			return ""
+		}
+		day := item.AddedAt.Local()
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+		// Weeks start on Monday.
+		monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return "Week of " + monday.Format("2006-01-02")
+	},
+}
+
+// IsValidGroupBy returns true if groupBy is a supported value for DigestOptions.GroupBy.
+func IsValidGroupBy(groupBy string) bool {
+	if groupBy == GroupByNone {
+		return true
+	}
+	_, ok := radarItemGroupers[groupBy]
+	return ok
+}
+
+// radarItemGroup is a named group of items within a digest section.
+type radarItemGroup struct {
+	Name  string
+	Items []RadarItem
+}
+
+// Heading returns the group's name with the number of items in it, e.g. "jvns.ca (3)".
+func (g radarItemGroup) Heading() string {
+	return fmt.Sprintf("%s (%d)", g.Name, len(g.Items))
+}
+
+// groupRadarItems splits the items into groups, keeping their order within each group.
+// Groups are sorted by name, except that weeks are newest first. Items with no value for
+// the grouping come last.
+func groupRadarItems(items []RadarItem, groupBy string) []radarItemGroup {
+	grouper, ok := radarItemGroupers[groupBy]
+	if !ok {
+		return []radarItemGroup{{Items: items}}
+	}
+
+	var groups []radarItemGroup
+	indexes := map[string]int{}
+	for _, item := range items {
+		name := grouper(item)
+		if name == "" {
+			name = ungroupedName
+		}
+		idx, ok := indexes[name]
+		if !ok {
+			idx = len(groups)
+			indexes[name] = idx
+			groups = append(groups, radarItemGroup{Name: name})
+		}
+		groups[idx].Items = append(groups[idx].Items, item)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Name == ungroupedName) != (groups[j].Name == ungroupedName) {
+			return groups[j].Name == ungroupedName
+		}
+		if groupBy == GroupByWeek {
+			return groups[i].Name > groups[j].Name
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
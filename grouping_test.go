@@ -0,0 +1,66 @@
+package radar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func groupNamesOf(groups []radarItemGroup) []string {
+	names := []string{}
+	for _, group := range groups {
+		names = append(names, group.Heading())
+	}
+	return names
+}
+
+func Test_groupRadarItems(t *testing.T) {
+	items := []RadarItem{
+		{URL: "https://www.jvns.ca/blog", Title: "Julia Evans", Tags: []string{"zines", "linux"}, AddedAt: time.Date(2026, time.October, 12, 9, 0, 0, 0, time.Local)},
+		{URL: "https://byparker.com", Title: "By Parker", Tags: []string{"blogs"}, AddedAt: time.Date(2026, time.October, 18, 9, 0, 0, 0, time.Local)},
+		{URL: "https://jvns.ca/zines", Title: "Wizard Zines", Tags: []string{"zines"}, AddedAt: time.Date(2026, time.October, 3, 9, 0, 0, 0, time.Local)},
+		{URL: "https://github.com", Title: "GitHub"},
+	}
+
+	assert.Equal(t, []string{"byparker.com (1)", "github.com (1)", "jvns.ca (2)"}, groupNamesOf(groupRadarItems(items, GroupByHost)))
+	assert.Equal(t, []string{"#blogs (1)", "#zines (2)", "Other (1)"}, groupNamesOf(groupRadarItems(items, GroupByTag)))
+	assert.Equal(t, []string{"Week of 2026-10-12 (2)", "Week of 2026-09-28 (1)", "Other (1)"}, groupNamesOf(groupRadarItems(items, GroupByWeek)))
+
+	groups := groupRadarItems(items, GroupByHost)
+	assert.Equal(t, []string{"Julia Evans", "Wizard Zines"}, titlesOf(groups[2].Items))
+
+	assert.Equal(t, []radarItemGroup{{Items: items}}, groupRadarItems(items, GroupByNone))
+}
+
+func TestIsValidGroupBy(t *testing.T) {
+	for _, groupBy := range []string{"", "host", "tag", "week"} {
+		assert.True(t, IsValidGroupBy(groupBy), groupBy)
+	}
+	assert.False(t, IsValidGroupBy("color"))
+}
+
+func Test_generateBody_grouped(t *testing.T) {
+	newLinks := []RadarItem{
+		{URL: "https://byparker.com", Title: "By Parker"},
+		{URL: "https://jvns.ca", Title: "Julia Evans", Tags: []string{"zines"}},
+		{URL: "https://jvns.ca/zines", Title: "Wizard Zines"},
+	}
+	oldLinks := []RadarItem{
+		{URL: "https://ben.balter.com", Title: "Ben Balter"},
+	}
+
+	for _, groupBy := range []string{GroupByHost, GroupByTag, GroupByWeek} {
+		body, err := generateBody(&tmplData{NewLinks: newLinks, OldLinks: oldLinks, Mention: "@parkr", GroupBy: groupBy})
+		assert.NoError(t, err)
+
+		items, err := extractLinkedTodosFromMarkdown(body)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, append(newLinks, oldLinks...), items, groupBy)
+	}
+
+	body, err := generateBody(&tmplData{NewLinks: newLinks, OldLinks: oldLinks, Mention: "@parkr", GroupBy: GroupByHost})
+	assert.NoError(t, err)
+	assert.Contains(t, body, "## New:\n\n### byparker.com (1)\n\n  * [ ] [By Parker](https://byparker.com)\n\n### jvns.ca (2)\n\n  * [ ] [Julia Evans](https://jvns.ca) — #zines\n  * [ ] [Wizard Zines](https://jvns.ca/zines)\n")
+	assert.Contains(t, body, "## *Previously:*\n\n### ben.balter.com (1)\n\n  * [ ] [Ben Balter](https://ben.balter.com)\n")
+}
@@ -9,24 +9,40 @@ import (
 )
 
 type healthHandler struct {
-	svc RadarItemsService
+	svc RadarItemsStorageService
 }
 
 // HealthResponse is the struct representing the JSON returned from the /health endpoint.
 type HealthResponse struct {
 	Ok bool
+	// LastGeneration is the outcome of the latest attempt to generate a digest, if any.
+	LastGeneration *GenerationStatus `json:",omitempty"`
+	// GitHubRateLimits is the remaining GitHub API quota for each resource used, e.g. "core".
+	GitHubRateLimits map[string]GitHubRateLimit `json:",omitempty"`
 }
 
 // ToGrohlData returns grohl data for this health response.
 func (r HealthResponse) ToGrohlData() grohl.Data {
-	return grohl.Data{
+	data := grohl.Data{
 		"ok": r.Ok,
 	}
+	if r.LastGeneration != nil {
+		data["generation_ok"] = r.LastGeneration.Ok
+		if r.LastGeneration.Reason != "" {
+			data["generation_reason"] = r.LastGeneration.Reason
+		}
+	}
+	for resource, limit := range r.GitHubRateLimits {
+		data["github_"+resource+"_remaining"] = limit.Remaining
+	}
+	return data
 }
 
 func newHealthResponse(ctx context.Context) HealthResponse {
 	return HealthResponse{
-		Ok: true,
+		Ok:               true,
+		LastGeneration:   latestGenerationStatus(),
+		GitHubRateLimits: latestGitHubRateLimits(),
 	}
 }
 
@@ -44,6 +60,6 @@ func (h healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 // NewHealthHandler returns a handler which provides health-related information.
-func NewHealthHandler(svc RadarItemsService) http.Handler {
+func NewHealthHandler(svc RadarItemsStorageService) http.Handler {
 	return healthHandler{svc: svc}
 }
@@ -29,6 +29,10 @@ func (h loggingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logCtx.SetStatter(nil, 0, "")
 	timer := logCtx.Timer(grohl.Data{})
 	h.handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), logCtxKey, logCtx)))
+	// Handlers may have added data (e.g. the authenticated token's name) since the timer started.
+	for key, value := range logCtx.Data() {
+		timer.Add(key, value)
+	}
 	timer.Finish()
 }
 
@@ -42,6 +46,12 @@ func GetLogContext(req *http.Request) *grohl.Context {
 	return req.Context().Value(logCtxKey).(*grohl.Context)
 }
 
+// getLogContextOrNil retrieves the grohl logging context for this request, if LoggingHandler set one.
+func getLogContextOrNil(req *http.Request) *grohl.Context {
+	logCtx, _ := req.Context().Value(logCtxKey).(*grohl.Context)
+	return logCtx
+}
+
 // Printf prints the input using grohl.
 func Printf(format string, args ...interface{}) {
 	grohl.Log(grohl.Data{"msg": fmt.Sprintf(format, args...)})
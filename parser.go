@@ -7,13 +7,13 @@ import (
 	"net"
 	"net/http"
 	"net/url"
-	"os"
 	"path"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/parkr/changelog"
+	"mvdan.cc/xurls/v2"
 )
 
 var titleExtractorRegexp = regexp.MustCompile("(?i)<title>(.+)</title>")
@@ -31,7 +31,7 @@ func titleForWebpage(urlString string) string {
 		return urlString
 	}
 
-	if inputURL.Hostname() == "github.com" && inputURL.Path != "" {
+	if isGitHubHost(inputURL.Hostname()) && inputURL.Path != "" {
 		if title := titleForGitHubReference(inputURL); title != "" {
 			return title
 		}
@@ -70,8 +70,10 @@ func titleForWebpage(urlString string) string {
 }
 
 func titleForGitHubReference(u *url.URL) string {
-	// Oof.
-	client := NewGitHubClient(os.Getenv("GITHUB_ACCESS_TOKEN"))
+	client, err := NewGitHubClientFromEnv()
+	if err != nil {
+		return ""
+	}
 	ctx := context.Background()
 
 	// Trim /files from the end and strip / from the beginning.
@@ -140,35 +142,201 @@ func isBinaryResource(resp *http.Response, u *url.URL) bool {
 	return !ok
 }
 
+// extractLinkedTodosFromMarkdown returns the unchecked todos in body.
 func extractLinkedTodosFromMarkdown(body string) ([]RadarItem, error) {
+	return extractTodosFromMarkdown(body, false)
+}
+
+// extractCheckedTodosFromMarkdown returns the checked-off todos in body.
+func extractCheckedTodosFromMarkdown(body string) ([]RadarItem, error) {
+	return extractTodosFromMarkdown(body, true)
+}
+
+// extractTodosFromMarkdown returns either the checked or the unchecked todos in body.
+func extractTodosFromMarkdown(body string, checked bool) ([]RadarItem, error) {
 	var items []RadarItem
+	err := eachTodoInMarkdown(body, checked, func(text string) {
+		item := parseLinkedTodo(text)
+		if item.URL != "" {
+			items = append(items, item)
+		} else {
+			Printf("unable to parse link [skip]: %s", text)
+		}
+	})
+	return items, err
+}
+
+// unparsableLinkedTodos returns the text of the unchecked todos in body which can't be parsed
+// into radar items.
+func unparsableLinkedTodos(body string) ([]string, error) {
+	var unparsable []string
+	err := eachTodoInMarkdown(body, false, func(text string) {
+		if parseLinkedTodo(text).URL == "" {
+			unparsable = append(unparsable, text)
+		}
+	})
+	return unparsable, err
+}
+
+// eachTodoInMarkdown calls fn with the text following the checkbox of either the checked or
+// the unchecked todos in body.
+func eachTodoInMarkdown(body string, checked bool, fn func(text string)) error {
 	chlog, err := changelog.NewChangelogFromReader(strings.NewReader(body))
 	if err != nil {
-		return items, err
+		return err
 	}
 	for _, version := range chlog.Versions {
-		for _, line := range version.History {
-			// Checked off, ignore.
-			if strings.HasPrefix(line.Summary, "[x]") || strings.HasPrefix(line.Summary, "[X]") {
-				continue
+		// Grouped digests list items under a subsection per group.
+		lines := append([]*changelog.ChangeLine{}, version.History...)
+		for _, subsection := range version.Subsections {
+			lines = append(lines, subsection.History...)
+		}
+		for _, line := range lines {
+			// The changelog parser splits a trailing " (word)" off into the reference; put it back.
+			summary := line.Summary
+			if line.Reference != "" && !strings.Contains(summary, "\n") {
+				summary += " (" + line.Reference + ")"
 			}
-			// Not checked off, parse and include.
-			title, url := parseMarkdownLink(line.Summary[len("[ ] "):])
-			if url != "" {
-				items = append(items, RadarItem{Title: title, URL: url})
-			} else {
-				Printf("unable to parse link [skip]: %s", line.Summary[len("[ ] "):])
+			// It also folds any following paragraphs into the last line of a list, so only
+			// the first line belongs to the todo.
+			summary, _, _ = strings.Cut(summary, "\n")
+			// Not a todo, e.g. an expired item, or not the kind we're looking for.
+			isChecked := strings.HasPrefix(summary, "[x] ") || strings.HasPrefix(summary, "[X] ")
+			if checked != isChecked || (!isChecked && !strings.HasPrefix(summary, "[ ] ")) {
+				continue
 			}
+			fn(summary[len("[ ] "):])
+		}
+	}
+	return nil
+}
+
+// linkedTodoSeparator separates a todo's link from its tags and note.
+const linkedTodoSeparator = " — "
+
+var tagRegexp = regexp.MustCompile(`^#([\p{L}\p{N}_/-]+)$`)
+
+// parseLinkedTodo parses the text following a todo's checkbox into a RadarItem, e.g.
+//
+//	[Title](https://example.com) — #golang #reading A note about it _(added 2 days ago)_ <!-- added=2026-10-16T09:00:00Z -->
+func parseLinkedTodo(text string) RadarItem {
+	link, suffix := splitLinkedTodo(text)
+	title, url := parseMarkdownLink(link)
+	item := RadarItem{Title: title, URL: url}
+	suffix = parseRadarItemMetadata(suffix, &item)
+	item.Tags, item.Note = parseTagsAndNote(suffix)
+	return item
+}
+
+// splitLinkedTodo splits the Markdown link from any text following it. The link runs up to
+// the tags, note or metadata which follow it, if any: its URL may contain spaces and
+// parentheses, so it ends at the last ")" before them.
+func splitLinkedTodo(text string) (string, string) {
+	boundaryIdx := strings.Index(text, "](")
+	if boundaryIdx < 0 {
+		return text, ""
+	}
+	endIdx := len(text)
+	for _, marker := range []string{linkedTodoSeparator, " <!--"} {
+		if idx := strings.Index(text[boundaryIdx:], marker); idx >= 0 && boundaryIdx+idx < endIdx {
+			endIdx = boundaryIdx + idx
+		}
+	}
+	closingParenIdx := strings.LastIndex(text[boundaryIdx:endIdx], ")")
+	if closingParenIdx < 0 {
+		return text, ""
+	}
+	closingParenIdx += boundaryIdx + 1
+	return text[:closingParenIdx], strings.TrimSpace(text[closingParenIdx:])
+}
+
+// parseTagsAndNote reads leading #tags and then a free-text note from the text following a todo's link.
+func parseTagsAndNote(suffix string) ([]string, string) {
+	suffix = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(suffix), strings.TrimSpace(linkedTodoSeparator)))
+
+	var tags []string
+	for suffix != "" {
+		token, rest, _ := strings.Cut(suffix, " ")
+		matches := tagRegexp.FindStringSubmatch(token)
+		if matches == nil {
+			break
+		}
+		tags = append(tags, matches[1])
+		suffix = strings.TrimSpace(rest)
+	}
+	return tags, suffix
+}
+
+// normalizeTags strips leading "#"s and whitespace from each tag, dropping blank, invalid
+// and duplicate tags.
+func normalizeTags(input []string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range input {
+		tag = strings.TrimLeft(strings.TrimSpace(tag), "#")
+		if tag == "" || seen[tag] || !tagRegexp.MatchString("#"+tag) {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+var emailHashtagRegexp = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]+)`)
+
+// extractHashtags finds every #tag in the text, ignoring anything inside a URL.
+func extractHashtags(text string) []string {
+	text = xurls.Strict().ReplaceAllString(text, " ")
+	var tags []string
+	for _, matches := range emailHashtagRegexp.FindAllStringSubmatch(text, -1) {
+		tags = append(tags, matches[1])
+	}
+	return normalizeTags(tags)
+}
+
+var linkedTodoLineRegexp = regexp.MustCompile(`^(\s*[-*+]\s+)\[ \]\s+(.+)$`)
+
+// linkedTodoCommentRegexp matches text containing at least one unchecked todo.
+var linkedTodoCommentRegexp = regexp.MustCompile(`(?m)^\s*[-*+]\s+\[ \]\s+\S`)
+
+// editLinkedTodoInMarkdown applies fn to every unchecked todo in body whose ID matches id
+// and re-renders those lines. It returns the new body and whether any line matched.
+func editLinkedTodoInMarkdown(body string, id int64, fn radarItemEdit) (string, bool) {
+	found := false
+	lines := strings.Split(body, "\n")
+	edited := make([]string, 0, len(lines))
+	for _, line := range lines {
+		matches := linkedTodoLineRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			edited = append(edited, line)
+			continue
+		}
+		item := parseLinkedTodo(matches[2])
+		if item.URL == "" || radarItemIDForURL(item.URL) != id {
+			edited = append(edited, line)
+			continue
+		}
+
+		found = true
+		checked, keep := fn(&item)
+		if !keep {
+			continue
+		}
+		checkbox := "[ ] "
+		if checked {
+			checkbox = "[x] "
 		}
+		edited = append(edited, matches[1]+checkbox+item.GetTodoMarkdown())
 	}
-	return items, nil
+	return strings.Join(edited, "\n"), found
 }
 
 func parseMarkdownLink(link string) (title string, url string) {
 	closingParenIdx := strings.LastIndex(link, ")")
 	boundaryIdx := strings.LastIndex(link, "](")
 	openingIdx := strings.Index(link, "[")
-	if closingParenIdx < 0 || boundaryIdx < 0 || openingIdx < 0 {
+	if closingParenIdx < 0 || boundaryIdx < 0 || openingIdx < 0 || closingParenIdx < boundaryIdx+2 || openingIdx > boundaryIdx {
 		return "", ""
 	}
 	return link[openingIdx+1 : boundaryIdx], link[boundaryIdx+2 : closingParenIdx]
@@ -200,3 +200,122 @@ func Test_titleForWebpage(t *testing.T) {
 		}
 	}
 }
+
+func Test_editLinkedTodoInMarkdown(t *testing.T) {
+	body := `A new day, @parkr! Here's what you have saved:
+
+## New:
+
+  * [ ] [Julia Evans](https://jvns.ca)
+  * [x] [Ben Balter](https://ben.balter.com)
+
+## *Previously:*
+
+  * [ ] [Google](https://google.com)
+`
+	id := radarItemIDForURL("https://jvns.ca")
+
+	checked, ok := editLinkedTodoInMarkdown(body, id, checkRadarItemEdit)
+	assert.True(t, ok)
+	assert.Contains(t, checked, "\n  * [x] [Julia Evans](https://jvns.ca)\n")
+	assert.Contains(t, checked, "\n  * [ ] [Google](https://google.com)\n")
+
+	deleted, ok := editLinkedTodoInMarkdown(body, id, deleteRadarItemEdit)
+	assert.True(t, ok)
+	assert.NotContains(t, deleted, "jvns.ca")
+	assert.Contains(t, deleted, "\n## New:\n\n  * [x] [Ben Balter](https://ben.balter.com)\n")
+
+	updated := RadarItem{}
+	renamed, ok := editLinkedTodoInMarkdown(body, id, updateRadarItemEdit(RadarItem{Title: "Wizard Zines"}, &updated))
+	assert.True(t, ok)
+	assert.Contains(t, renamed, "\n  * [ ] [Wizard Zines](https://jvns.ca)\n")
+	assert.Equal(t, RadarItem{Title: "Wizard Zines", URL: "https://jvns.ca"}, updated)
+
+	// Tags and notes survive edits.
+	tagged := "  * [ ] [Julia Evans](https://jvns.ca) — #zines #linux Start with the networking one\n"
+	renamed, ok = editLinkedTodoInMarkdown(tagged, id, updateRadarItemEdit(RadarItem{Title: "Wizard Zines"}, &updated))
+	assert.True(t, ok)
+	assert.Equal(t, "  * [ ] [Wizard Zines](https://jvns.ca) — #zines #linux Start with the networking one\n", renamed)
+
+	// Checked-off items can't be edited.
+	unchanged, ok := editLinkedTodoInMarkdown(body, radarItemIDForURL("https://ben.balter.com"), deleteRadarItemEdit)
+	assert.False(t, ok)
+	assert.Equal(t, body, unchanged)
+}
+
+func Test_extractLinkedTodosFromMarkdown_tagsAndNotes(t *testing.T) {
+	body := `
+## New:
+
+  * [ ] [Julia Evans](https://jvns.ca) — #zines #linux Start with the networking one
+  * [ ] [Ben Balter](https://ben.balter.com) — Worth a read (later)
+  * [ ] [By Parker](https://byparker.com) — #golang
+
+/cc @parkr
+`
+	expected := []RadarItem{
+		{Title: "Julia Evans", URL: "https://jvns.ca", Tags: []string{"zines", "linux"}, Note: "Start with the networking one"},
+		{Title: "Ben Balter", URL: "https://ben.balter.com", Note: "Worth a read (later)"},
+		{Title: "By Parker", URL: "https://byparker.com", Tags: []string{"golang"}},
+	}
+
+	items, err := extractLinkedTodosFromMarkdown(body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, items)
+
+	for _, item := range expected {
+		rendered, err := extractLinkedTodosFromMarkdown("- [ ] " + item.GetTodoMarkdown())
+		assert.NoError(t, err)
+		assert.Equal(t, []RadarItem{item}, rendered)
+	}
+}
+
+func Test_extractLinkedTodosFromMarkdown_parenthesesAndSpaces(t *testing.T) {
+	body := "## New:\n\n- [ ] [Talk (video)](https://youtu.be/x watch later)\n- [ ] [Talk (slides)](https://example.com/a b) — #talks Later (maybe) <!-- source=api -->\n- [ ] [Wiki](https://en.wikipedia.org/wiki/Go_(programming_language))\n"
+
+	items, err := extractLinkedTodosFromMarkdown(body)
+	assert.NoError(t, err)
+	assert.Equal(t, []RadarItem{
+		{Title: "Talk (video)", URL: "https://youtu.be/x watch later"},
+		{Title: "Talk (slides)", URL: "https://example.com/a b", Tags: []string{"talks"}, Note: "Later (maybe)", Source: "api"},
+		{Title: "Wiki", URL: "https://en.wikipedia.org/wiki/Go_(programming_language)"},
+	}, items)
+
+	// Malformed links are skipped rather than crashing the parser.
+	for _, link := range []string{"[Talk (video)](https://youtu.be/x", "[Talk)](", "](x)[y"} {
+		title, url := parseMarkdownLink(link)
+		assert.Empty(t, title, "Link: %q", link)
+		assert.Empty(t, url, "Link: %q", link)
+	}
+}
+
+func Test_normalizeTags(t *testing.T) {
+	assert.Equal(t, []string{"golang", "reading"}, normalizeTags([]string{"#golang", " reading ", "", "golang", "not a tag"}))
+	assert.Nil(t, normalizeTags(nil))
+}
+
+func Test_extractHashtags(t *testing.T) {
+	assert.Equal(t,
+		[]string{"golang", "reading"},
+		extractHashtags("#golang https://example.com/#anchor and #reading, not issue#1"),
+	)
+}
+
+func Test_extractCheckedTodosFromMarkdown(t *testing.T) {
+	body := "## New:\n\n- [x] [Julia Evans](https://jvns.ca) — #zines\n- [ ] [By Parker](https://byparker.com)\n- [X] [Wizard Zines](https://wizardzines.com)\n\n## Expired:\n\n- [Old News](https://example.com)\n"
+
+	items, err := extractCheckedTodosFromMarkdown(body)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, "https://jvns.ca", items[0].URL)
+		assert.Equal(t, []string{"zines"}, items[0].Tags)
+		assert.Equal(t, "https://wizardzines.com", items[1].URL)
+	}
+
+	items, err = extractLinkedTodosFromMarkdown(body)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "https://byparker.com", items[0].URL)
+	}
+}
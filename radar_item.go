@@ -2,8 +2,12 @@ package radar
 
 import (
 	"context"
-	"fmt"
+	"hash/fnv"
 	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v53/github"
 	"github.com/pkg/errors"
@@ -13,13 +17,37 @@ import (
 //
 // RadarItem.GetTitle() is defined in parser.go. Use that to fetch the title!
 type RadarItem struct {
-	ID    int64
-	URL   string
-	Title string
+	ID    int64  `json:"ID"`
+	URL   string `json:"URL"`
+	Title string `json:"Title"`
+
+	// Tags are optional labels for the item, without the leading "#".
+	Tags []string `json:"Tags,omitempty"`
+	// Note is optional free text about the item.
+	Note string `json:"Note,omitempty"`
+
+	// Source is how the item arrived, e.g. SourceEmail or SourceAPI.
+	Source string `json:"Source,omitempty"`
+	// SubmittedBy is who added the item: an email address, or the name of an API token.
+	SubmittedBy string `json:"SubmittedBy,omitempty"`
+	// AddedAt is when the item was first added to the radar, if known.
+	AddedAt time.Time `json:"AddedAt,omitzero"`
+	// CheckedAt is when the item was checked off, for items listed by ListDone. Backends which
+	// don't record the exact time use the time the item's digest was retired.
+	CheckedAt time.Time `json:"CheckedAt,omitzero"`
 
 	parsedURL *url.URL
 }
 
+const (
+	// SourceEmail marks items which arrived via the Mailgun email webhook.
+	SourceEmail = "email"
+	// SourceAPI marks items which arrived via the JSON API.
+	SourceAPI = "api"
+	// SourcePoster marks items which arrived via the radar-poster web form.
+	SourcePoster = "poster"
+)
+
 func (r *RadarItem) GetHostname() string {
 	if r.parsedURL == nil {
 		var err error
@@ -37,10 +65,90 @@ func (r *RadarItem) GetMarkdown() string {
 	return "[" + r.GetTitle() + "](" + r.URL + ")"
 }
 
+// GetTodoMarkdown returns the item's checklist line without the checkbox: the Markdown link,
+// followed by its tags, note and age, if any, and a comment with its metadata.
+func (r *RadarItem) GetTodoMarkdown() string {
+	markdown := r.GetMarkdown()
+	var extras []string
+	for _, tag := range r.Tags {
+		extras = append(extras, "#"+tag)
+	}
+	if note := strings.Join(strings.Fields(r.Note), " "); note != "" {
+		extras = append(extras, note)
+	}
+	if age := radarItemAge(r.AddedAt, time.Now()); age != "" {
+		extras = append(extras, "_("+age+")_")
+	}
+	if len(extras) > 0 {
+		markdown += linkedTodoSeparator + strings.Join(extras, " ")
+	}
+	if metadata := formatRadarItemMetadata(r); metadata != "" {
+		markdown += " " + metadata
+	}
+	return markdown
+}
+
 func (r *RadarItem) GetFormatted() string {
 	return r.Title + " (" + r.URL + ")"
 }
 
+// radarItemIDForURL derives a stable ID for an item which is stored only as a Markdown
+// checklist line. It's truncated to 53 bits so JavaScript clients can round-trip it.
+func radarItemIDForURL(u string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(u))
+	return int64(h.Sum64() >> 11)
+}
+
+// withURLIDs assigns each item an ID derived from its URL.
+func withURLIDs(items []RadarItem) []RadarItem {
+	for i := range items {
+		items[i].ID = radarItemIDForURL(items[i].URL)
+	}
+	return items
+}
+
+// radarItemEdit modifies an item in place and reports whether it should now be checked off,
+// and whether it should be kept at all.
+type radarItemEdit func(item *RadarItem) (checked, keep bool)
+
+func checkRadarItemEdit(item *RadarItem) (bool, bool) {
+	return true, true
+}
+
+func deleteRadarItemEdit(item *RadarItem) (bool, bool) {
+	return false, false
+}
+
+// updateRadarItemEdit returns an edit which sets the non-blank fields of m (URL, title, tags, note) on the item and
+// records the result in updated.
+func updateRadarItemEdit(m RadarItem, updated *RadarItem) radarItemEdit {
+	return func(item *RadarItem) (bool, bool) {
+		if m.URL != "" {
+			item.URL = m.URL
+			item.parsedURL = nil
+		}
+		if m.Title != "" {
+			item.Title = m.Title
+		}
+		if m.Tags != nil {
+			item.Tags = m.Tags
+		}
+		if m.Note != "" {
+			item.Note = m.Note
+		}
+		*updated = *item
+		return false, true
+	}
+}
+
+// sortDoneRadarItems sorts items by CheckedAt, most recent first.
+func sortDoneRadarItems(items []RadarItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CheckedAt.After(items[j].CheckedAt)
+	})
+}
+
 type RadarItems []RadarItem
 
 func (r RadarItems) Len() int {
@@ -60,30 +168,101 @@ type RadarItemsService struct {
 	githubClient *github.Client
 	owner        string
 	repoName     string
+	// label marks radar issues. Blank means DefaultRadarLabel.
+	label string
+	// current caches the number of the open radar issue. Nil means it's looked up every time.
+	current *githubIssueCache
 }
 
+var _ RadarItemsStorageService = RadarItemsService{}
+
 // NewRadarItemsService creates a new RadarItemsService with all the proper fields initialized.
-func NewRadarItemsService(githubClient *github.Client, owner, repoName string) RadarItemsService {
+// Radar issues are found by, and given, the label, or DefaultRadarLabel if it's blank.
+func NewRadarItemsService(githubClient *github.Client, owner, repoName, label string) RadarItemsService {
 	return RadarItemsService{
 		githubClient: githubClient,
 		owner:        owner,
 		repoName:     repoName,
+		label:        label,
+		current:      &githubIssueCache{},
+	}
+}
+
+func (rs RadarItemsService) radarLabel() string {
+	if rs.label == "" {
+		return DefaultRadarLabel
+	}
+	return rs.label
+}
+
+// githubIssueCache remembers the number of the open radar issue between requests, until
+// generation rolls it over.
+type githubIssueCache struct {
+	mu     sync.Mutex
+	number int
+}
+
+// get returns the cached issue number, or 0 if there isn't one.
+func (c *githubIssueCache) get() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.number
+}
+
+// set caches the issue number. 0 clears the cache.
+func (c *githubIssueCache) set(number int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.number = number
+}
+
+// currentRadarIssue returns the open radar issue, or nil if there isn't one. The cached issue
+// is fetched directly, unless it's since been closed, e.g. by another process.
+func (rs RadarItemsService) currentRadarIssue(ctx context.Context) (*github.Issue, error) {
+	if number := rs.current.get(); number != 0 {
+		issue, _, err := rs.githubClient.Issues.Get(ctx, rs.owner, rs.repoName, number)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error fetching radar issue %d", number)
+		}
+		if issue.GetState() == "open" {
+			return issue, nil
+		}
+		rs.current.set(0)
+	}
+
+	issue, err := getPreviousRadarIssue(ctx, rs.githubClient, rs.owner, rs.repoName, rs.radarLabel())
+	if err != nil {
+		return nil, err
 	}
+	if issue != nil {
+		rs.current.set(issue.GetNumber())
+	}
+	return issue, nil
 }
 
 // GetGitHubIssue fetches the GitHub issue.
 func (rs RadarItemsService) GetGitHubIssue(ctx context.Context) (*github.Issue, error) {
-	issue := getPreviousRadarIssue(ctx, rs.githubClient, rs.owner, rs.repoName)
+	issue, err := rs.currentRadarIssue(ctx)
+	if err != nil {
+		return nil, err
+	}
 	if issue == nil {
 		newIssue, _, err := rs.githubClient.Issues.Create(ctx, rs.owner, rs.repoName, &github.IssueRequest{
-			Title:  github.String(getTitle()),
-			Body:   github.String("Welcome to your new radar!"),
-			Labels: &labels,
+			Title:  github.String(getTitle(time.Now())),
+			Body:   github.String(welcomeDigestBody),
+			Labels: &[]string{rs.radarLabel()},
 		})
 		if err != nil {
 			return nil, err
 		}
 		issue = newIssue
+		rs.current.set(issue.GetNumber())
 	}
 	return issue, nil
 }
@@ -94,19 +273,173 @@ func (rs RadarItemsService) List(ctx context.Context) ([]RadarItem, []RadarItem,
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "error fetching open issue")
 	}
-	return extractGitHubLinks(ctx, rs.githubClient, rs.owner, rs.repoName, issue)
+	oldItems, newItems, err := extractGitHubLinks(ctx, rs.githubClient, rs.owner, rs.repoName, issue)
+	return withURLIDs(oldItems), withURLIDs(newItems), err
 }
 
-// Create adds a RadarItem to the GitHub issue.
-func (rs RadarItemsService) Create(ctx context.Context, m RadarItem) error {
+// Get returns the unchecked item with the given ID from the GitHub issue or its comments.
+func (rs RadarItemsService) Get(ctx context.Context, id int64) (*RadarItem, error) {
+	oldItems, newItems, err := rs.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range append(newItems, oldItems...) {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+	return nil, ErrRadarItemNotFound
+}
+
+// Update changes the title and/or URL of an item wherever it appears in the GitHub issue or its comments.
+func (rs RadarItemsService) Update(ctx context.Context, id int64, m RadarItem) (*RadarItem, error) {
+	updated := &RadarItem{}
+	if err := rs.edit(ctx, id, updateRadarItemEdit(m, updated)); err != nil {
+		return nil, err
+	}
+	updated.ID = radarItemIDForURL(updated.URL)
+	return updated, nil
+}
+
+// Check checks off an item wherever it appears in the GitHub issue or its comments.
+func (rs RadarItemsService) Check(ctx context.Context, id int64) error {
+	return rs.edit(ctx, id, checkRadarItemEdit)
+}
+
+// ListDone returns the items checked off in radar issues closed at or after since. GitHub
+// doesn't record when a checkbox was ticked, so each item's CheckedAt is when its issue was closed.
+func (rs RadarItemsService) ListDone(ctx context.Context, since time.Time) ([]RadarItem, error) {
+	issues, err := listClosedRadarIssues(ctx, rs.githubClient, rs.owner, rs.repoName, rs.radarLabel(), since)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error listing closed issues")
+	}
+	var items []RadarItem
+	for _, issue := range issues {
+		checkedItems, err := extractCheckedGitHubLinks(ctx, rs.githubClient, rs.owner, rs.repoName, issue)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "error listing checked items in issue %d", issue.GetNumber())
+		}
+		for _, item := range checkedItems {
+			item.CheckedAt = issue.GetClosedAt().Time
+			items = append(items, item)
+		}
+	}
+	sortDoneRadarItems(items)
+	return withURLIDs(items), nil
+}
+
+// Delete removes an item wherever it appears in the GitHub issue or its comments.
+// Comments which are left empty are deleted.
+func (rs RadarItemsService) Delete(ctx context.Context, id int64) error {
+	return rs.edit(ctx, id, deleteRadarItemEdit)
+}
+
+func (rs RadarItemsService) edit(ctx context.Context, id int64, fn radarItemEdit) error {
 	issue, err := rs.GetGitHubIssue(ctx)
 	if err != nil {
 		return errors.WithMessage(err, "error fetching open issue")
 	}
+
+	found := false
+	if body, ok := editLinkedTodoInMarkdown(issue.GetBody(), id, fn); ok {
+		found = true
+		_, _, err := rs.githubClient.Issues.Edit(ctx, rs.owner, rs.repoName, issue.GetNumber(), &github.IssueRequest{
+			Body: github.String(body),
+		})
+		if err != nil {
+			return errors.WithMessage(err, "error editing issue body")
+		}
+	}
+
+	comments, err := listGitHubComments(ctx, rs.githubClient, rs.owner, rs.repoName, issue.GetNumber())
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		body, ok := editLinkedTodoInMarkdown(comment.GetBody(), id, fn)
+		if !ok {
+			continue
+		}
+		found = true
+		if strings.TrimSpace(body) == "" {
+			_, err = rs.githubClient.Issues.DeleteComment(ctx, rs.owner, rs.repoName, comment.GetID())
+		} else {
+			_, _, err = rs.githubClient.Issues.EditComment(ctx, rs.owner, rs.repoName, comment.GetID(), &github.IssueComment{
+				Body: github.String(body),
+			})
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "error editing comment %d", comment.GetID())
+		}
+	}
+
+	if !found {
+		return ErrRadarItemNotFound
+	}
+	return nil
+}
+
+// GetDigest fetches the GitHub issue as a Digest.
+func (rs RadarItemsService) GetDigest(ctx context.Context) (*Digest, error) {
+	issue, err := rs.GetGitHubIssue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return digestFromGitHubIssue(issue), nil
+}
+
+// GenerateDigest creates a new GitHub issue with all unchecked items and closes the previous one.
+func (rs RadarItemsService) GenerateDigest(ctx context.Context, opts DigestOptions) (*Digest, error) {
+	issue, err := generateGitHubRadarIssue(ctx, rs, opts)
+	if err != nil {
+		return nil, err
+	}
+	return digestFromGitHubIssue(issue), nil
+}
+
+// GenerateSummary creates a GitHub issue summarizing the radar issues open during the period.
+// It's labeled "radar-summary" rather than with the radar label, so it isn't mistaken for a digest.
+func (rs RadarItemsService) GenerateSummary(ctx context.Context, opts SummaryOptions) (*Digest, error) {
+	activity, err := githubRadarActivity(ctx, rs.githubClient, rs.owner, rs.repoName, rs.radarLabel(), opts)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error summarizing radar issues")
+	}
+	issue, _, err := rs.githubClient.Issues.Create(ctx, rs.owner, rs.repoName, &github.IssueRequest{
+		Title:  github.String(opts.Title()),
+		Body:   github.String(generateSummaryBody(activity, opts)),
+		Labels: &summaryLabels,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return digestFromGitHubIssue(issue), nil
+}
+
+// Create adds a RadarItem to the GitHub issue.
+func (rs RadarItemsService) Create(ctx context.Context, m RadarItem) (*RadarItem, error) {
+	issue, err := rs.GetGitHubIssue(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error fetching open issue")
+	}
+	oldItems, newItems, err := extractGitHubLinks(ctx, rs.githubClient, rs.owner, rs.repoName, issue)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error listing radar items")
+	}
+	if err := checkDuplicate(m.URL, withURLIDs(newItems), withURLIDs(oldItems)); err != nil {
+		return nil, err
+	}
+	m.Title = m.GetTitle()
+	if m.AddedAt.IsZero() {
+		m.AddedAt = time.Now()
+	}
 	_, _, err = rs.githubClient.Issues.CreateComment(ctx, rs.owner, rs.repoName, *issue.Number, &github.IssueComment{
-		Body: github.String(fmt.Sprintf("- [ ] [%s](%s)", m.GetTitle(), m.URL)),
+		Body: github.String("- [ ] " + m.GetTodoMarkdown()),
 	})
-	return err
+	if err != nil {
+		return nil, err
+	}
+	m.ID = radarItemIDForURL(m.URL)
+	return &m, nil
 }
 
 // Shutdown closes the database connection.
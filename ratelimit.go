@@ -0,0 +1,172 @@
+package radar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// githubMaxRetries is how many times a failed GitHub API request is retried.
+	githubMaxRetries = 3
+	// githubMaxRetryWait is the longest we'll wait before retrying. Rate limits which reset
+	// later than this fail straight away rather than tie up the caller.
+	githubMaxRetryWait = time.Minute
+	// githubRetryBackoff is the wait before the first retry of a server error. It doubles
+	// with each retry.
+	githubRetryBackoff = time.Second
+)
+
+// GitHubRateLimit is the API quota for one of GitHub's rate limit resources, e.g. "core", as of
+// the latest response.
+type GitHubRateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+var githubRateLimits struct {
+	sync.Mutex
+	byResource map[string]GitHubRateLimit
+}
+
+// recordGitHubRateLimit remembers the quota reported in a response's headers, if any.
+func recordGitHubRateLimit(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	resource := header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+
+	githubRateLimits.Lock()
+	defer githubRateLimits.Unlock()
+	if githubRateLimits.byResource == nil {
+		githubRateLimits.byResource = map[string]GitHubRateLimit{}
+	}
+	githubRateLimits.byResource[resource] = GitHubRateLimit{Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}
+}
+
+// latestGitHubRateLimits returns the latest quota for each resource used since the process
+// started, or nil if GitHub hasn't been called.
+func latestGitHubRateLimits() map[string]GitHubRateLimit {
+	githubRateLimits.Lock()
+	defer githubRateLimits.Unlock()
+	if len(githubRateLimits.byResource) == 0 {
+		return nil
+	}
+	limits := make(map[string]GitHubRateLimit, len(githubRateLimits.byResource))
+	for resource, limit := range githubRateLimits.byResource {
+		limits[resource] = limit
+	}
+	return limits
+}
+
+// githubRetryTransport retries GitHub API requests which hit a rate limit or fail with a
+// server error, waiting as long as GitHub asks, and records the remaining quota.
+//
+// Rate limited requests weren't processed, so they're always retried. Server errors and
+// network failures are only retried for idempotent methods: a POST may have succeeded anyway.
+type githubRetryTransport struct {
+	base http.RoundTripper
+	// sleep waits for d, or until ctx is done. Tests replace it.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newGitHubRetryTransport(base http.RoundTripper) *githubRetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &githubRetryTransport{base: base, sleep: sleepContext}
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *githubRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && hasBody(req) {
+			// The body was consumed by the last attempt.
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if resp != nil {
+			recordGitHubRateLimit(resp.Header)
+		}
+
+		wait, retry := githubRetryDelay(req, resp, err, attempt)
+		if !retry || attempt >= githubMaxRetries || wait > githubMaxRetryWait || (hasBody(req) && req.GetBody == nil) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		Printf("github: retrying %s %s in %s (attempt %d of %d)", req.Method, req.URL.Path, wait, attempt+1, githubMaxRetries)
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// hasBody returns true if the request has a body, which has to be rewound to retry it.
+func hasBody(req *http.Request) bool {
+	return req.Body != nil && req.Body != http.NoBody
+}
+
+// githubRetryDelay returns how long to wait before retrying the request, and whether it
+// should be retried at all.
+func githubRetryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := githubRetryBackoff << attempt
+	idempotent := req.Method != http.MethodPost
+
+	if err != nil {
+		return backoff, idempotent && req.Context().Err() == nil
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusForbidden:
+		// Secondary rate limits say how long to wait.
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		// Primary rate limits say when the quota resets.
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				return time.Until(time.Unix(reset, 0)) + time.Second, true
+			}
+		}
+		// Otherwise, it's a plain permission error.
+		return 0, false
+	case resp.StatusCode >= 500:
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(seconds) * time.Second, idempotent
+		}
+		return backoff, idempotent
+	}
+	return 0, false
+}
@@ -0,0 +1,171 @@
+package radar
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestGitHubRetryTransport returns a transport which records how long it waited instead of sleeping.
+func newTestGitHubRetryTransport(waits *[]time.Duration) *githubRetryTransport {
+	transport := newGitHubRetryTransport(nil)
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return nil
+	}
+	return transport
+}
+
+func TestGitHubRetryTransport(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second).Unix()
+	for _, tc := range []struct {
+		name          string
+		method        string
+		responses     []func(w http.ResponseWriter)
+		expectedCode  int
+		expectedCalls int
+		expectedWaits []time.Duration
+	}{
+		{
+			name:   "server errors are retried with backoff",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			},
+			expectedCode:  http.StatusOK,
+			expectedCalls: 3,
+			expectedWaits: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:   "server errors on POST aren't retried",
+			method: http.MethodPost,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			},
+			expectedCode:  http.StatusBadGateway,
+			expectedCalls: 1,
+		},
+		{
+			name:   "secondary rate limits honour Retry-After",
+			method: http.MethodPost,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "7")
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			expectedCode:  http.StatusOK,
+			expectedCalls: 2,
+			expectedWaits: []time.Duration{7 * time.Second},
+		},
+		{
+			name:   "primary rate limits wait for the reset",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+					w.WriteHeader(http.StatusForbidden)
+				},
+			},
+			expectedCode:  http.StatusOK,
+			expectedCalls: 2,
+		},
+		{
+			name:   "rate limits which reset too far off aren't waited for",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "3600")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+			},
+			expectedCode:  http.StatusTooManyRequests,
+			expectedCalls: 1,
+		},
+		{
+			name:   "permission errors aren't retried",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusForbidden) },
+			},
+			expectedCode:  http.StatusForbidden,
+			expectedCalls: 1,
+		},
+		{
+			name:   "retries give up eventually",
+			method: http.MethodGet,
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedCalls: 4,
+			expectedWaits: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost {
+					assert.Equal(t, `{"body":"hi"}`, string(body), "the body is sent with every attempt")
+				}
+				calls++
+				if calls <= len(tc.responses) {
+					tc.responses[calls-1](w)
+					return
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := &http.Client{Transport: newTestGitHubRetryTransport(&waits)}
+			req, err := http.NewRequest(tc.method, server.URL, strings.NewReader(`{"body":"hi"}`))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.expectedWaits != nil {
+				assert.Equal(t, tc.expectedWaits, waits)
+			}
+			for _, wait := range waits {
+				assert.LessOrEqual(t, wait, githubMaxRetryWait)
+			}
+		})
+	}
+}
+
+func TestRecordGitHubRateLimit(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "5000")
+	header.Set("X-RateLimit-Remaining", "4321")
+	header.Set("X-RateLimit-Reset", "1792310400")
+	header.Set("X-RateLimit-Resource", "core")
+	recordGitHubRateLimit(header)
+
+	// Responses without rate limit headers are ignored.
+	recordGitHubRateLimit(http.Header{})
+
+	limits := latestGitHubRateLimits()
+	assert.Equal(t, GitHubRateLimit{Limit: 5000, Remaining: 4321, Reset: time.Unix(1792310400, 0)}, limits["core"])
+
+	resp := newHealthResponse(t.Context())
+	assert.Equal(t, 4321, resp.GitHubRateLimits["core"].Remaining)
+	assert.Equal(t, 4321, resp.ToGrohlData()["github_core_remaining"])
+}
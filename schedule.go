@@ -0,0 +1,97 @@
+package radar
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleLookback is how far back Schedule.Prev looks for a scheduled time.
+const scheduleLookback = 366 * 24 * time.Hour
+
+// Schedule is when to generate the radar: a cron expression in a time zone.
+type Schedule struct {
+	expression string
+	schedule   cron.Schedule
+	location   *time.Location
+}
+
+// ParseSchedule parses a standard five-field cron expression, e.g. "30 7 * * 1-5" for 07:30 on
+// weekdays, or a descriptor like "@daily". It's interpreted in the named IANA time zone, e.g.
+// "America/Los_Angeles", or in the local time zone if timezone is blank.
+func ParseSchedule(expression, timezone string) (*Schedule, error) {
+	location := time.Local
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+	}
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", expression, err)
+	}
+	if schedule.Next(time.Now().In(location)).IsZero() {
+		return nil, fmt.Errorf("invalid schedule %q: it never runs", expression)
+	}
+	return &Schedule{expression: expression, schedule: schedule, location: location}, nil
+}
+
+// ScheduleForHour returns a schedule for the top of the given hour (00-23) every day.
+func ScheduleForHour(hour, timezone string) (*Schedule, error) {
+	if len(hour) != 2 {
+		return nil, fmt.Errorf("hour must be in 24-hour time, e.g. 03, got %q", hour)
+	}
+	return ParseSchedule("0 "+hour+" * * *", timezone)
+}
+
+// String describes the schedule, e.g. "30 7 * * 1-5 (America/Los_Angeles)".
+func (s *Schedule) String() string {
+	return fmt.Sprintf("%s (%s)", s.expression, s.location)
+}
+
+// Location is the time zone the schedule is interpreted in.
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+// Next returns the first scheduled time after t.
+func (s *Schedule) Next(t time.Time) time.Time {
+	return s.schedule.Next(t.In(s.location))
+}
+
+// Prev returns the last scheduled time at or before t, or the zero time if there was none in
+// the last year.
+func (s *Schedule) Prev(t time.Time) time.Time {
+	// cron can only look forwards, so find a window which contains a scheduled time and then
+	// walk forwards through it.
+	for window := time.Hour; window <= scheduleLookback; window *= 2 {
+		next := s.Next(t.Add(-window))
+		if next.IsZero() {
+			// The schedule never fires, e.g. on February 30th.
+			return time.Time{}
+		}
+		if next.After(t) {
+			continue
+		}
+		for {
+			following := s.Next(next)
+			if following.After(t) {
+				return next
+			}
+			next = following
+		}
+	}
+	return time.Time{}
+}
+
+// Missed returns the last scheduled time at or before now, if the latest digest was generated
+// before it, e.g. because the process was down at the time.
+func (s *Schedule) Missed(lastGeneratedAt, now time.Time) (time.Time, bool) {
+	prev := s.Prev(now)
+	if prev.IsZero() || !lastGeneratedAt.Before(prev) {
+		return time.Time{}, false
+	}
+	return prev, true
+}
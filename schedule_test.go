@@ -0,0 +1,85 @@
+package radar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := ParseSchedule("30 7 * * 1-5", "America/Los_Angeles")
+	require.NoError(t, err)
+	assert.Equal(t, "30 7 * * 1-5 (America/Los_Angeles)", schedule.String())
+	assert.Equal(t, "America/Los_Angeles", schedule.Location().String())
+
+	_, err = ParseSchedule("not a schedule", "")
+	assert.EqualError(t, err, `invalid schedule "not a schedule": expected exactly 5 fields, found 3: [not a schedule]`)
+
+	_, err = ParseSchedule("0 0 30 2 *", "UTC")
+	assert.EqualError(t, err, `invalid schedule "0 0 30 2 *": it never runs`)
+
+	_, err = ParseSchedule("@daily", "Nowhere/Special")
+	assert.ErrorContains(t, err, `invalid time zone "Nowhere/Special"`)
+
+	schedule, err = ParseSchedule("@daily", "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, schedule.Location())
+}
+
+func TestScheduleForHour(t *testing.T) {
+	schedule, err := ScheduleForHour("03", "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, "0 03 * * * (UTC)", schedule.String())
+
+	_, err = ScheduleForHour("3", "UTC")
+	assert.EqualError(t, err, `hour must be in 24-hour time, e.g. 03, got "3"`)
+
+	_, err = ScheduleForHour("24", "UTC")
+	assert.Error(t, err)
+}
+
+func TestSchedule_NextAndPrev(t *testing.T) {
+	schedule, err := ParseSchedule("30 7 * * 1-5", "America/Los_Angeles")
+	require.NoError(t, err)
+	la := schedule.Location()
+
+	friday := time.Date(2026, time.October, 16, 12, 0, 0, 0, la)
+	assert.Equal(t, time.Date(2026, time.October, 19, 7, 30, 0, 0, la), schedule.Next(friday))
+	assert.Equal(t, time.Date(2026, time.October, 16, 7, 30, 0, 0, la), schedule.Prev(friday))
+
+	// Prev includes t itself, Next doesn't.
+	fridayMorning := time.Date(2026, time.October, 16, 7, 30, 0, 0, la)
+	assert.Equal(t, fridayMorning, schedule.Prev(fridayMorning))
+	assert.Equal(t, time.Date(2026, time.October, 19, 7, 30, 0, 0, la), schedule.Next(fridayMorning))
+
+	// The time zone is honoured whatever the zone of t.
+	sundayUTC := time.Date(2026, time.October, 18, 20, 0, 0, 0, time.UTC)
+	assert.True(t, time.Date(2026, time.October, 16, 7, 30, 0, 0, la).Equal(schedule.Prev(sundayUTC)))
+
+	monthly, err := ParseSchedule("@monthly", "UTC")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), monthly.Prev(sundayUTC))
+
+	yearly, err := ParseSchedule("@yearly", "UTC")
+	require.NoError(t, err)
+	assert.True(t, yearly.Prev(time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC)).IsZero(), "more than a year ago")
+}
+
+func TestSchedule_Missed(t *testing.T) {
+	schedule, err := ScheduleForHour("03", "UTC")
+	require.NoError(t, err)
+	now := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+	due := time.Date(2026, time.October, 18, 3, 0, 0, 0, time.UTC)
+
+	missed, ok := schedule.Missed(due.AddDate(0, 0, -1), now)
+	assert.True(t, ok)
+	assert.Equal(t, due, missed)
+
+	_, ok = schedule.Missed(due, now)
+	assert.False(t, ok, "generated on time")
+
+	_, ok = schedule.Missed(due.Add(time.Hour), now)
+	assert.False(t, ok, "generated since")
+}
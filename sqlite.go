@@ -0,0 +1,545 @@
+package radar
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite" // Registers the pure-Go "sqlite" driver.
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS digests (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	title      TEXT NOT NULL,
+	body       TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL,
+	closed_at  DATETIME
+);
+
+CREATE TABLE IF NOT EXISTS radar_items (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	url          TEXT NOT NULL,
+	title        TEXT NOT NULL DEFAULT '',
+	source       TEXT NOT NULL DEFAULT '',
+	submitted_by TEXT NOT NULL DEFAULT '',
+	tags         TEXT NOT NULL DEFAULT '',
+	note         TEXT NOT NULL DEFAULT '',
+	checked      BOOLEAN NOT NULL DEFAULT 0,
+	expired_at   DATETIME,
+	digest_id    INTEGER NOT NULL REFERENCES digests(id),
+	created_at   DATETIME NOT NULL,
+	updated_at   DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS radar_items_digest_id ON radar_items(digest_id);
+
+CREATE TABLE IF NOT EXISTS summaries (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	period     TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	body       TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL
+);
+`
+
+// sqliteAddedColumns are columns added to radar_items after it was first created. Databases
+// created before a column existed have it added when they're opened.
+var sqliteAddedColumns = []struct{ name, definition string }{
+	{"tags", "TEXT NOT NULL DEFAULT ''"},
+	{"note", "TEXT NOT NULL DEFAULT ''"},
+	{"expired_at", "DATETIME"},
+	{"submitted_by", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// migrateSQLite creates any missing tables, adds any missing columns, and rewrites times
+// written before they were stored in UTC.
+func migrateSQLite(db *sql.DB) error {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('radar_items')`)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, column := range sqliteAddedColumns {
+		if existing[column.name] {
+			continue
+		}
+		if _, err := db.Exec(`ALTER TABLE radar_items ADD COLUMN ` + column.name + ` ` + column.definition); err != nil {
+			return errors.WithMessagef(err, "error adding column %q", column.name)
+		}
+	}
+	return migrateSQLiteUpdatedAt(db)
+}
+
+// migrateSQLiteUpdatedAt rewrites radar_items.updated_at in UTC, as ListDone compares it as text.
+// Times used to be written in the server's time zone, e.g. "2026-10-18 09:30:00 -0700 PDT".
+// Times already in UTC end in "+00:00".
+func migrateSQLiteUpdatedAt(db *sql.DB) error {
+	rows, err := db.Query(`SELECT id, updated_at FROM radar_items WHERE updated_at NOT LIKE '%+00:00'`)
+	if err != nil {
+		return err
+	}
+	updatedAt := map[int64]time.Time{}
+	for rows.Next() {
+		var id int64
+		var t time.Time
+		if err := rows.Scan(&id, &t); err != nil {
+			rows.Close()
+			return errors.WithMessage(err, "error reading updated_at")
+		}
+		updatedAt[id] = t
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(updatedAt) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for id, t := range updatedAt {
+		if _, err := tx.Exec(`UPDATE radar_items SET updated_at = ? WHERE id = ?`, t.UTC(), id); err != nil {
+			return errors.WithMessage(err, "error rewriting updated_at in UTC")
+		}
+	}
+	return tx.Commit()
+}
+
+// sqliteTags converts tags to the space-separated form stored in the tags column.
+// Nil tags are NULL, so that updates can leave the column unchanged.
+func sqliteTags(tags []string) interface{} {
+	if tags == nil {
+		return nil
+	}
+	return strings.Join(tags, " ")
+}
+
+// sqlQuerier is satisfied by both *sql.DB and *sql.Tx.
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// SQLiteRadarItemsService stores radar items in a local SQLite database instead of GitHub.
+//
+// Each item remembers the digest it was added to. Unchecked items from earlier digests
+// are "old" items; unchecked items added to the current digest are "new" items.
+type SQLiteRadarItemsService struct {
+	db *sql.DB
+	// dir is the directory containing the database, which summaries are written alongside.
+	dir string
+}
+
+var _ RadarItemsStorageService = &SQLiteRadarItemsService{}
+
+// NewSQLiteRadarItemsService opens (or creates) the SQLite database at the given path and migrates it.
+func NewSQLiteRadarItemsService(path string) (*SQLiteRadarItemsService, error) {
+	// Start transactions with BEGIN IMMEDIATE, so that each one holds the write lock from the
+	// start and checks made at its beginning still hold when it writes. Write times in a fixed
+	// format so that, being UTC too, they compare correctly as text.
+	db, err := sql.Open("sqlite", path+"?_txlock=immediate&_time_format=sqlite")
+	if err != nil {
+		return nil, errors.WithMessagef(err, "error opening sqlite database %q", path)
+	}
+	// SQLite allows only one writer at a time; serialize access rather than returning SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+	// Wait for other processes sharing the database to finish writing, e.g. generating a digest.
+	if _, err := db.Exec(`PRAGMA busy_timeout = 30000`); err != nil {
+		db.Close()
+		return nil, errors.WithMessage(err, "error setting sqlite busy timeout")
+	}
+
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, errors.WithMessage(err, "error migrating sqlite database")
+	}
+
+	return &SQLiteRadarItemsService{db: db, dir: filepath.Dir(path)}, nil
+}
+
+// GetDigest returns the current open digest, creating one if none exists.
+func (s *SQLiteRadarItemsService) GetDigest(ctx context.Context) (*Digest, error) {
+	return s.currentDigest(ctx, s.db)
+}
+
+func (s *SQLiteRadarItemsService) currentDigest(ctx context.Context, q sqlQuerier) (*Digest, error) {
+	digest := &Digest{}
+	err := q.QueryRowContext(ctx,
+		`SELECT id, title, body, created_at FROM digests WHERE closed_at IS NULL ORDER BY id DESC LIMIT 1`,
+	).Scan(&digest.Number, &digest.Title, &digest.Body, &digest.CreatedAt)
+	if err == sql.ErrNoRows {
+		return s.insertDigest(ctx, q, getTitle(time.Now()), welcomeDigestBody)
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "error fetching current digest")
+	}
+	digest.CreatedAt = digest.CreatedAt.UTC()
+	return digest, nil
+}
+
+func (s *SQLiteRadarItemsService) insertDigest(ctx context.Context, q sqlQuerier, title, body string) (*Digest, error) {
+	createdAt := time.Now().UTC()
+	result, err := q.ExecContext(ctx,
+		`INSERT INTO digests (title, body, created_at) VALUES (?, ?, ?)`,
+		title, body, createdAt,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error creating digest")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &Digest{Number: int(id), Title: title, Body: body, CreatedAt: createdAt}, nil
+}
+
+// List returns all unchecked radar items, split into old and new items.
+func (s *SQLiteRadarItemsService) List(ctx context.Context) ([]RadarItem, []RadarItem, error) {
+	return s.list(ctx, s.db)
+}
+
+func (s *SQLiteRadarItemsService) list(ctx context.Context, q sqlQuerier) ([]RadarItem, []RadarItem, error) {
+	digest, err := s.currentDigest(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT id, url, title, source, submitted_by, tags, note, created_at, digest_id FROM radar_items WHERE checked = 0 AND expired_at IS NULL ORDER BY id`,
+	)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "error listing radar items")
+	}
+	defer rows.Close()
+
+	var oldItems []RadarItem
+	var newItems []RadarItem
+	for rows.Next() {
+		var item RadarItem
+		var digestID int
+		var tags string
+		if err := rows.Scan(&item.ID, &item.URL, &item.Title, &item.Source, &item.SubmittedBy, &tags, &item.Note, &item.AddedAt, &digestID); err != nil {
+			return nil, nil, errors.WithMessage(err, "error reading radar item")
+		}
+		item.Tags = normalizeTags(strings.Fields(tags))
+		if digestID == digest.Number {
+			newItems = append(newItems, item)
+		} else {
+			oldItems = append(oldItems, item)
+		}
+	}
+	return oldItems, newItems, rows.Err()
+}
+
+// Create stores a new radar item in the current digest. Checking for duplicates and storing the
+// item happen in one transaction, so another writer can't add the same link in between.
+func (s *SQLiteRadarItemsService) Create(ctx context.Context, m RadarItem) (*RadarItem, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	oldItems, newItems, err := s.list(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDuplicate(m.URL, newItems, oldItems); err != nil {
+		return nil, err
+	}
+
+	digest, err := s.currentDigest(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Title = m.GetTitle()
+	if m.AddedAt.IsZero() {
+		m.AddedAt = time.Now()
+	}
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO radar_items (url, title, source, submitted_by, tags, note, digest_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.URL, m.Title, m.Source, m.SubmittedBy, strings.Join(m.Tags, " "), m.Note, digest.Number, m.AddedAt.UTC(), time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error creating radar item")
+	}
+	if m.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+	return &m, tx.Commit()
+}
+
+// Get returns the unchecked item with the given ID.
+func (s *SQLiteRadarItemsService) Get(ctx context.Context, id int64) (*RadarItem, error) {
+	item := &RadarItem{}
+	var tags string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT id, url, title, source, submitted_by, tags, note, created_at FROM radar_items WHERE id = ? AND checked = 0 AND expired_at IS NULL`, id,
+	).Scan(&item.ID, &item.URL, &item.Title, &item.Source, &item.SubmittedBy, &tags, &item.Note, &item.AddedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrRadarItemNotFound
+	}
+	if err != nil {
+		return nil, errors.WithMessage(err, "error fetching radar item")
+	}
+	item.Tags = normalizeTags(strings.Fields(tags))
+	return item, nil
+}
+
+// Update changes the URL, title, tags and/or note of an unchecked item.
+func (s *SQLiteRadarItemsService) Update(ctx context.Context, id int64, m RadarItem) (*RadarItem, error) {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE radar_items SET url = COALESCE(NULLIF(?, ''), url), title = COALESCE(NULLIF(?, ''), title), tags = COALESCE(?, tags), note = COALESCE(NULLIF(?, ''), note), updated_at = ? WHERE id = ? AND checked = 0 AND expired_at IS NULL`,
+		m.URL, m.Title, sqliteTags(m.Tags), m.Note, time.Now().UTC(), id,
+	)
+	if err := s.requireAffected(result, err); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, id)
+}
+
+// Check marks an item as checked off.
+func (s *SQLiteRadarItemsService) Check(ctx context.Context, id int64) error {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE radar_items SET checked = 1, updated_at = ? WHERE id = ? AND checked = 0 AND expired_at IS NULL`, time.Now().UTC(), id,
+	)
+	return s.requireAffected(result, err)
+}
+
+// ListDone returns the items checked off at or after since. Checked items can't be changed,
+// so the time an item was last updated is when it was checked off.
+func (s *SQLiteRadarItemsService) ListDone(ctx context.Context, since time.Time) ([]RadarItem, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, url, title, source, submitted_by, tags, note, created_at, updated_at FROM radar_items WHERE checked = 1 AND updated_at >= ? ORDER BY id`,
+		since.UTC(),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error listing checked radar items")
+	}
+	defer rows.Close()
+
+	var items []RadarItem
+	for rows.Next() {
+		var item RadarItem
+		var tags string
+		if err := rows.Scan(&item.ID, &item.URL, &item.Title, &item.Source, &item.SubmittedBy, &tags, &item.Note, &item.AddedAt, &item.CheckedAt); err != nil {
+			return nil, errors.WithMessage(err, "error reading radar item")
+		}
+		item.Tags = normalizeTags(strings.Fields(tags))
+		items = append(items, item)
+	}
+	sortDoneRadarItems(items)
+	return items, rows.Err()
+}
+
+// Delete removes an unchecked item from the database.
+func (s *SQLiteRadarItemsService) Delete(ctx context.Context, id int64) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM radar_items WHERE id = ? AND checked = 0 AND expired_at IS NULL`, id)
+	return s.requireAffected(result, err)
+}
+
+// requireAffected returns ErrRadarItemNotFound if the statement didn't change any rows.
+func (s *SQLiteRadarItemsService) requireAffected(result sql.Result, err error) error {
+	if err != nil {
+		return errors.WithMessage(err, "error writing radar item")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrRadarItemNotFound
+	}
+	return nil
+}
+
+// GenerateDigest closes the current digest and opens a new one listing every unchecked item.
+func (s *SQLiteRadarItemsService) GenerateDigest(ctx context.Context, opts DigestOptions) (*Digest, error) {
+	// The transaction takes the write lock before reading anything, so that another process
+	// generating a digest at the same time finishes first and this one sees its digest.
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	previous, err := s.currentDigest(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if isGeneratedToday(previous.Title, previous.Body, opts) {
+		return nil, ErrDigestAlreadyGenerated
+	}
+
+	data := &tmplData{Mention: opts.Mention, GroupBy: opts.GroupBy}
+	data.OldLinks, data.NewLinks, err = s.list(ctx, tx)
+	if err != nil {
+		return nil, &DigestGenerationError{GenerationReasonReadFailed, err}
+	}
+	now := time.Now()
+	data.expire(opts.ExpireAfter, now)
+	sort.Stable(RadarItems(data.NewLinks))
+	sort.Stable(RadarItems(data.OldLinks))
+	sort.Stable(RadarItems(data.ExpiredLinks))
+
+	for _, expired := range data.ExpiredLinks {
+		if _, err := tx.ExecContext(ctx, `UPDATE radar_items SET expired_at = ?, updated_at = ? WHERE id = ?`, now.UTC(), now.UTC(), expired.ID); err != nil {
+			return nil, errors.WithMessage(err, "error expiring radar item")
+		}
+	}
+
+	body, err := generateBody(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE digests SET closed_at = ? WHERE id = ?`, time.Now().UTC(), previous.Number); err != nil {
+		return nil, errors.WithMessage(err, "error closing previous digest")
+	}
+
+	digest, err := s.insertDigest(ctx, tx, getTitle(opts.now()), body)
+	if err != nil {
+		return nil, err
+	}
+
+	return digest, tx.Commit()
+}
+
+// GenerateSummary stores a summary of the period in the summaries table.
+func (s *SQLiteRadarItemsService) GenerateSummary(ctx context.Context, opts SummaryOptions) (*Digest, error) {
+	activity, err := s.activity(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	body := generateSummaryBody(activity, opts)
+	// The table is only a record: write the summary alongside the database to be read.
+	path, err := writeSummaryFile(s.dir, opts, body)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO summaries (period, title, body, created_at) VALUES (?, ?, ?, ?)`,
+		opts.Period, opts.Title(), body, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error creating summary")
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &Digest{Number: int(id), Title: opts.Title(), URL: path, Body: body}, nil
+}
+
+// activity reads what happened during the period from the radar items' timestamps. The backlog
+// for each digest is the items which had been added, and not yet checked off or expired, when
+// the digest was generated.
+func (s *SQLiteRadarItemsService) activity(ctx context.Context, opts SummaryOptions) (*radarActivity, error) {
+	type sqliteItemState struct {
+		RadarItem
+		checked   bool
+		expiredAt sql.NullTime
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, url, title, source, submitted_by, tags, note, checked, created_at, updated_at, expired_at FROM radar_items ORDER BY id`,
+	)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error listing radar items")
+	}
+	var items []sqliteItemState
+	for rows.Next() {
+		var item sqliteItemState
+		var tags string
+		if err := rows.Scan(&item.ID, &item.URL, &item.Title, &item.Source, &item.SubmittedBy, &tags, &item.Note, &item.checked, &item.AddedAt, &item.CheckedAt, &item.expiredAt); err != nil {
+			rows.Close()
+			return nil, errors.WithMessage(err, "error reading radar item")
+		}
+		item.Tags = normalizeTags(strings.Fields(tags))
+		if !item.checked {
+			item.CheckedAt = time.Time{}
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	activity := &radarActivity{}
+	for _, item := range items {
+		activity.addAdded(opts, item.RadarItem)
+		if item.checked && opts.includes(item.CheckedAt) {
+			activity.Done = append(activity.Done, item.RadarItem)
+		}
+		if item.expiredAt.Valid && opts.includes(item.expiredAt.Time) {
+			activity.Expired = append(activity.Expired, item.RadarItem)
+		}
+	}
+
+	digestRows, err := s.db.QueryContext(ctx, `SELECT created_at FROM digests ORDER BY id`)
+	if err != nil {
+		return nil, errors.WithMessage(err, "error listing digests")
+	}
+	defer digestRows.Close()
+	for digestRows.Next() {
+		var generatedAt time.Time
+		if err := digestRows.Scan(&generatedAt); err != nil {
+			return nil, errors.WithMessage(err, "error reading digest")
+		}
+		if !opts.includes(generatedAt) {
+			continue
+		}
+		size := 0
+		for _, item := range items {
+			if item.AddedAt.After(generatedAt) ||
+				(item.checked && !item.CheckedAt.After(generatedAt)) ||
+				(item.expiredAt.Valid && !item.expiredAt.Time.After(generatedAt)) {
+				continue
+			}
+			size++
+		}
+		activity.Backlog = append(activity.Backlog, backlogSize{Date: generatedAt, Size: size})
+	}
+	if err := digestRows.Err(); err != nil {
+		return nil, err
+	}
+
+	activity.finish()
+	return activity, nil
+}
+
+// Shutdown closes the database connection.
+func (s *SQLiteRadarItemsService) Shutdown(ctx context.Context) {
+	if err := s.db.Close(); err != nil {
+		Printf("error closing sqlite database: %+v", err)
+	}
+}
@@ -0,0 +1,322 @@
+package radar
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLiteRadarItemsService(t *testing.T) *SQLiteRadarItemsService {
+	svc, err := NewSQLiteRadarItemsService(filepath.Join(t.TempDir(), "radar.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.Shutdown(context.Background()) })
+	return svc
+}
+
+func TestSQLiteRadarItemsService_CreateAndList(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSQLiteRadarItemsService(t)
+
+	created, err := svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", Source: SourceEmail})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), created.ID)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker", Source: SourceAPI})
+	assert.NoError(t, err)
+
+	oldItems, newItems, err := svc.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, oldItems)
+	if assert.Len(t, newItems, 2) {
+		assert.Equal(t, int64(1), newItems[0].ID)
+		assert.Equal(t, "https://jvns.ca", newItems[0].URL)
+		assert.Equal(t, "Julia Evans", newItems[0].Title)
+		assert.Equal(t, SourceEmail, newItems[0].Source)
+		assert.False(t, newItems[0].AddedAt.IsZero())
+		assert.Equal(t, int64(2), newItems[1].ID)
+	}
+}
+
+func TestSQLiteRadarItemsService_Create_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "radar.db")
+	svc, err := NewSQLiteRadarItemsService(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.Shutdown(context.Background()) })
+	digest, err := svc.GetDigest(ctx)
+	require.NoError(t, err)
+
+	// Another process is adding the same link.
+	other, err := NewSQLiteRadarItemsService(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { other.Shutdown(context.Background()) })
+	tx, err := other.db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO radar_items (url, title, digest_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+		"https://jvns.ca", "Julia Evans", digest.Number, time.Now(), time.Now(),
+	)
+	require.NoError(t, err)
+
+	created := make(chan error)
+	go func() {
+		_, err := svc.Create(ctx, RadarItem{URL: "https://jvns.ca/", Title: "Julia Evans"})
+		created <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, tx.Commit())
+
+	var duplicateErr *DuplicateRadarItemError
+	assert.ErrorAs(t, <-created, &duplicateErr)
+	_, newItems, err := svc.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, newItems, 1)
+}
+
+func TestSQLiteRadarItemsService_GenerateDigest(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSQLiteRadarItemsService(t)
+
+	first, err := svc.GetDigest(ctx)
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans"})
+	assert.NoError(t, err)
+
+	digest, err := svc.GenerateDigest(ctx, DigestOptions{Mention: "@monalisa"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Number, digest.Number)
+	assert.Equal(t, getTitle(time.Now()), digest.Title)
+	assert.Contains(t, digest.Body, "A new day, @monalisa!")
+	assert.Contains(t, digest.Body, "[ ] [Julia Evans](https://jvns.ca)")
+
+	current, err := svc.GetDigest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, digest, current)
+
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker"})
+	assert.NoError(t, err)
+	oldItems, newItems, err := svc.List(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, oldItems, 1) {
+		assert.Equal(t, "https://jvns.ca", oldItems[0].URL)
+	}
+	if assert.Len(t, newItems, 1) {
+		assert.Equal(t, "https://byparker.com", newItems[0].URL)
+	}
+
+	// Today's digest has already been generated, so this is a no-op.
+	_, err = svc.GenerateDigest(ctx, DigestOptions{Mention: "@monalisa"})
+	assert.ErrorIs(t, err, ErrDigestAlreadyGenerated)
+	current, err = svc.GetDigest(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, digest.Number, current.Number)
+}
+
+func TestSQLiteRadarItemsService_GenerateDigest_Location(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSQLiteRadarItemsService(t)
+
+	// Whichever of these is on a different date to the local time zone.
+	location := time.FixedZone("UTC+14", 14*60*60)
+	if time.Now().In(location).Format("2006-01-02") == time.Now().Format("2006-01-02") {
+		location = time.FixedZone("UTC-12", -12*60*60)
+	}
+
+	digest, err := svc.GenerateDigest(ctx, DigestOptions{Location: location})
+	require.NoError(t, err)
+	assert.Equal(t, getTitle(time.Now().In(location)), digest.Title)
+	assert.NotEqual(t, getTitle(time.Now()), digest.Title)
+
+	// It's already been generated for the day in that time zone, but not in the local one.
+	_, err = svc.GenerateDigest(ctx, DigestOptions{Location: location})
+	assert.ErrorIs(t, err, ErrDigestAlreadyGenerated)
+	assert.False(t, isGeneratedToday(digest.Title, digest.Body, DigestOptions{}))
+}
+
+func TestSQLiteRadarItemsService_UpdateCheckDelete(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSQLiteRadarItemsService(t)
+
+	_, err := svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans"})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker"})
+	assert.NoError(t, err)
+
+	item, err := svc.Update(ctx, 1, RadarItem{Title: "Wizard Zines"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Wizard Zines", item.Title)
+	assert.Equal(t, "https://jvns.ca", item.URL)
+
+	assert.NoError(t, svc.Check(ctx, 1))
+	assert.Equal(t, ErrRadarItemNotFound, svc.Check(ctx, 1))
+	_, err = svc.Get(ctx, 1)
+	assert.Equal(t, ErrRadarItemNotFound, err)
+
+	assert.NoError(t, svc.Delete(ctx, 2))
+	assert.Equal(t, ErrRadarItemNotFound, svc.Delete(ctx, 2))
+
+	oldItems, newItems, err := svc.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, oldItems)
+	assert.Empty(t, newItems)
+}
+
+func TestSQLiteRadarItemsService_TagsAndNote(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "radar.db")
+
+	// Start from a database created before tags and notes existed.
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	schema := sqliteSchema
+	for _, column := range sqliteAddedColumns {
+		schema = regexp.MustCompile(`\n\t`+column.name+`\s[^\n]*`).ReplaceAllString(schema, "")
+	}
+	assert.NotContains(t, schema, "submitted_by")
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	svc, err := NewSQLiteRadarItemsService(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.Shutdown(context.Background()) })
+
+	created, err := svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", Tags: []string{"zines", "linux"}, Note: "Start with networking"})
+	require.NoError(t, err)
+
+	item, err := svc.Get(ctx, created.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"zines", "linux"}, item.Tags)
+	assert.Equal(t, "Start with networking", item.Note)
+
+	item, err = svc.Update(ctx, created.ID, RadarItem{Title: "Wizard Zines"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"zines", "linux"}, item.Tags)
+	assert.Equal(t, "Start with networking", item.Note)
+
+	item, err = svc.Update(ctx, created.ID, RadarItem{Tags: []string{}})
+	assert.NoError(t, err)
+	assert.Empty(t, item.Tags)
+
+	digest, err := svc.GenerateDigest(ctx, DigestOptions{Mention: "@parkr"})
+	assert.NoError(t, err)
+	assert.Contains(t, digest.Body, "[ ] [Wizard Zines](https://jvns.ca) — Start with networking <!-- added=")
+}
+
+func TestSQLiteRadarItemsService_Expiry(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSQLiteRadarItemsService(t)
+
+	stale, err := svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", AddedAt: time.Now().AddDate(0, 0, -40)})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker"})
+	assert.NoError(t, err)
+
+	digest, err := svc.GenerateDigest(ctx, DigestOptions{ExpireAfter: 30 * 24 * time.Hour})
+	assert.NoError(t, err)
+	assert.Contains(t, digest.Body, "## Expired:\n\n  * [Julia Evans](https://jvns.ca)")
+
+	oldItems, newItems, err := svc.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"By Parker"}, titlesOf(oldItems))
+	assert.Empty(t, newItems)
+
+	_, err = svc.Get(ctx, stale.ID)
+	assert.ErrorIs(t, err, ErrRadarItemNotFound)
+}
+
+func TestSQLiteRadarItemsService_ListDone(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSQLiteRadarItemsService(t)
+
+	_, err := svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans", Tags: []string{"zines"}})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker"})
+	assert.NoError(t, err)
+
+	before := time.Now()
+	assert.NoError(t, svc.Check(ctx, 1))
+
+	doneItems, err := svc.ListDone(ctx, time.Time{})
+	assert.NoError(t, err)
+	if assert.Len(t, doneItems, 1) {
+		assert.Equal(t, "Julia Evans", doneItems[0].Title)
+		assert.Equal(t, []string{"zines"}, doneItems[0].Tags)
+		assert.WithinDuration(t, before, doneItems[0].CheckedAt, time.Minute)
+	}
+
+	doneItems, err = svc.ListDone(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, doneItems)
+}
+
+func TestSQLiteRadarItemsService_ListDone_LocalTimes(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "radar.db")
+	svc, err := NewSQLiteRadarItemsService(path)
+	require.NoError(t, err)
+	digest, err := svc.GetDigest(ctx)
+	require.NoError(t, err)
+	svc.Shutdown(ctx)
+
+	// Items checked off before times were written in UTC, by servers in far-apart time zones.
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	now := time.Now()
+	for title, checkedAt := range map[string]time.Time{
+		"Julia Evans": now.Add(-10 * time.Minute).In(time.FixedZone("HST", -10*60*60)),
+		"By Parker":   now.Add(-2 * time.Hour).In(time.FixedZone("LINT", 14*60*60)),
+	} {
+		_, err = db.Exec(
+			`INSERT INTO radar_items (url, title, checked, digest_id, created_at, updated_at) VALUES (?, ?, 1, ?, ?, ?)`,
+			"https://example.com/"+title, title, digest.Number, checkedAt, checkedAt,
+		)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	svc, err = NewSQLiteRadarItemsService(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.Shutdown(context.Background()) })
+	doneItems, err := svc.ListDone(ctx, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Julia Evans"}, titlesOf(doneItems))
+}
+
+func TestSQLiteRadarItemsService_GenerateSummary(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestSQLiteRadarItemsService(t)
+
+	_, err := svc.Create(ctx, RadarItem{URL: "https://jvns.ca", Title: "Julia Evans"})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://byparker.com", Title: "By Parker"})
+	assert.NoError(t, err)
+	_, err = svc.Create(ctx, RadarItem{URL: "https://xkcd.com", Title: "xkcd", AddedAt: time.Now().Add(-100 * 24 * time.Hour)})
+	assert.NoError(t, err)
+	assert.NoError(t, svc.Check(ctx, 1))
+	_, err = svc.GenerateDigest(ctx, DigestOptions{ExpireAfter: 30 * 24 * time.Hour})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	opts := SummaryOptions{Period: SummaryWeekly, Start: today, End: today.AddDate(0, 0, 1)}
+	summary, err := svc.GenerateSummary(ctx, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Number)
+	assert.Equal(t, filepath.Join(svc.dir, "summaries", opts.Name()+".md"), summary.URL)
+	written, err := os.ReadFile(summary.URL)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "# "+opts.Title()+"\n\n"+summary.Body, string(written))
+	}
+	assert.Contains(t, summary.Body, "- 2 links added\n- 1 link read\n- 1 link expired\n")
+	// xkcd was on the first digest, and By Parker is left on the second.
+	assert.Contains(t, summary.Body, "Backlog went from 1 to 1 (+0) over 2 digests")
+	assert.Contains(t, summary.Body, "## Read:\n\n  * [Julia Evans](https://jvns.ca)")
+	assert.Contains(t, summary.Body, "## Expired:\n\n  * [xkcd](https://xkcd.com)")
+}
@@ -0,0 +1,98 @@
+package radar
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrRadarItemNotFound is returned when no unchecked radar item has the requested ID.
+var ErrRadarItemNotFound = errors.New("radar item not found")
+
+// ErrDigestAlreadyGenerated is returned by GenerateDigest when today's digest already exists.
+var ErrDigestAlreadyGenerated = errors.New("radar already generated today")
+
+// ErrDigestGenerationInProgress is returned by GenerateDigest when another generator holds the lock.
+var ErrDigestGenerationInProgress = errors.New("radar generation already in progress")
+
+// generationLockTTL is how long a generation lock is honoured, in case its holder died without releasing it.
+const generationLockTTL = 10 * time.Minute
+
+// welcomeDigestBody is the body of the placeholder digest created when there isn't one yet.
+const welcomeDigestBody = "Welcome to your new radar!"
+
+// isGeneratedToday returns true if the digest is today's in the options' time zone, rather
+// than an earlier one or a placeholder created today because there wasn't one yet.
+func isGeneratedToday(title, body string, opts DigestOptions) bool {
+	return title == getTitle(opts.now()) && !strings.HasSuffix(strings.TrimSpace(body), welcomeDigestBody)
+}
+
+// Digest is a single rendering of the radar, e.g. the daily GitHub issue.
+type Digest struct {
+	// Number identifies the digest within its backend, e.g. the issue number.
+	Number int
+	// Title is the human-readable title, e.g. "Radar for 2026-10-18".
+	Title string
+	// URL is where the digest can be viewed, if anywhere.
+	URL string
+	// Body is the rendered Markdown body of the digest.
+	Body string
+	// CreatedAt is when the digest was generated.
+	CreatedAt time.Time
+}
+
+// DigestOptions configures how a digest is rendered.
+type DigestOptions struct {
+	// Mention is who to greet in the digest, e.g. "@parkr".
+	Mention string
+	// GroupBy groups the items in each section under a heading: GroupByNone, GroupByHost,
+	// GroupByTag or GroupByWeek.
+	GroupBy string
+	// ExpireAfter moves items added longer ago than this into an "Expired" section, which
+	// isn't carried over to the next digest. Zero means items never expire.
+	ExpireAfter time.Duration
+	// Location is the time zone whose date titles the digest and decides whether today's has
+	// already been generated, e.g. the schedule's. Nil means the local time zone.
+	Location *time.Location
+}
+
+// now returns the current time in the digest's time zone.
+func (o DigestOptions) now() time.Time {
+	if o.Location == nil {
+		return time.Now()
+	}
+	return time.Now().In(o.Location)
+}
+
+// RadarItemsStorageService is a backend which stores radar items and renders them into digests.
+type RadarItemsStorageService interface {
+	// List the radar items. The first slice contains items carried over from the
+	// previous digest, the second contains items added since.
+	List(ctx context.Context) ([]RadarItem, []RadarItem, error)
+	// Store a new radar item, returning it as stored. Returns a *DuplicateRadarItemError
+	// if an unchecked item with the same canonical URL already exists.
+	Create(ctx context.Context, m RadarItem) (*RadarItem, error)
+	// Fetch a single unchecked radar item.
+	Get(ctx context.Context, id int64) (*RadarItem, error)
+	// Change the title and/or URL of an unchecked radar item. Blank fields are left as-is.
+	Update(ctx context.Context, id int64, m RadarItem) (*RadarItem, error)
+	// Check off a radar item so it isn't carried forward to the next digest.
+	Check(ctx context.Context, id int64) error
+	// List the radar items checked off at or after since, most recently checked first.
+	// Each item's CheckedAt is set. A zero since lists every checked item.
+	ListDone(ctx context.Context, since time.Time) ([]RadarItem, error)
+	// Remove a radar item entirely.
+	Delete(ctx context.Context, id int64) error
+	// Fetch the current digest, creating one if none exists.
+	GetDigest(ctx context.Context) (*Digest, error)
+	// Roll the current items into a new digest and retire the previous one. Only one
+	// generator runs at a time: the others get ErrDigestGenerationInProgress. If today's
+	// digest already exists, nothing changes and ErrDigestAlreadyGenerated is returned.
+	GenerateDigest(ctx context.Context, opts DigestOptions) (*Digest, error)
+	// Summarize the items added, checked off and expired over a period, and how the backlog
+	// changed, into a new summary alongside the digests.
+	GenerateSummary(ctx context.Context, opts SummaryOptions) (*Digest, error)
+	// Shut down the service.
+	Shutdown(ctx context.Context)
+}
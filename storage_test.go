@@ -0,0 +1,183 @@
+package radar
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fakeRadarItemsStorageService is an in-memory RadarItemsStorageService for testing handlers.
+type fakeRadarItemsStorageService struct {
+	sync.Mutex
+
+	oldItems  []RadarItem
+	newItems  []RadarItem
+	doneItems []RadarItem
+	digest    *Digest
+	err       error
+	// createErrs are returned by the next calls to Create, one per call.
+	createErrs []error
+
+	// listCalls counts calls to List.
+	listCalls int
+}
+
+var _ RadarItemsStorageService = &fakeRadarItemsStorageService{}
+
+func (f *fakeRadarItemsStorageService) List(ctx context.Context) ([]RadarItem, []RadarItem, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.listCalls++
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return append([]RadarItem{}, f.oldItems...), append([]RadarItem{}, f.newItems...), nil
+}
+
+func (f *fakeRadarItemsStorageService) Create(ctx context.Context, m RadarItem) (*RadarItem, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	if len(f.createErrs) > 0 {
+		err := f.createErrs[0]
+		f.createErrs = f.createErrs[1:]
+		return nil, err
+	}
+	if err := checkDuplicate(m.URL, f.newItems, f.oldItems); err != nil {
+		return nil, err
+	}
+	f.newItems = append(f.newItems, m)
+	return &m, nil
+}
+
+func (f *fakeRadarItemsStorageService) find(id int64) (*RadarItem, error) {
+	for i := range f.newItems {
+		if f.newItems[i].ID == id {
+			return &f.newItems[i], nil
+		}
+	}
+	for i := range f.oldItems {
+		if f.oldItems[i].ID == id {
+			return &f.oldItems[i], nil
+		}
+	}
+	return nil, ErrRadarItemNotFound
+}
+
+func (f *fakeRadarItemsStorageService) Get(ctx context.Context, id int64) (*RadarItem, error) {
+	f.Lock()
+	defer f.Unlock()
+	item, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	found := *item
+	return &found, nil
+}
+
+func (f *fakeRadarItemsStorageService) Update(ctx context.Context, id int64, m RadarItem) (*RadarItem, error) {
+	f.Lock()
+	defer f.Unlock()
+	item, err := f.find(id)
+	if err != nil {
+		return nil, err
+	}
+	updated := &RadarItem{}
+	updateRadarItemEdit(m, updated)(item)
+	return updated, nil
+}
+
+func (f *fakeRadarItemsStorageService) Check(ctx context.Context, id int64) error {
+	f.Lock()
+	item, err := f.find(id)
+	if err == nil {
+		checked := *item
+		checked.CheckedAt = time.Now()
+		f.doneItems = append([]RadarItem{checked}, f.doneItems...)
+	}
+	f.Unlock()
+	return f.Delete(ctx, id)
+}
+
+func (f *fakeRadarItemsStorageService) ListDone(ctx context.Context, since time.Time) ([]RadarItem, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	var items []RadarItem
+	for _, item := range f.doneItems {
+		if !item.CheckedAt.Before(since) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (f *fakeRadarItemsStorageService) Delete(ctx context.Context, id int64) error {
+	f.Lock()
+	defer f.Unlock()
+	if _, err := f.find(id); err != nil {
+		return err
+	}
+	f.newItems = removeRadarItem(f.newItems, id)
+	f.oldItems = removeRadarItem(f.oldItems, id)
+	return nil
+}
+
+func removeRadarItem(items []RadarItem, id int64) []RadarItem {
+	var kept []RadarItem
+	for _, item := range items {
+		if item.ID != id {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func (f *fakeRadarItemsStorageService) GetDigest(ctx context.Context) (*Digest, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.digest == nil {
+		f.digest = &Digest{Number: 1, Title: getTitle(time.Now())}
+	}
+	return f.digest, f.err
+}
+
+func (f *fakeRadarItemsStorageService) GenerateDigest(ctx context.Context, opts DigestOptions) (*Digest, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.oldItems = append(f.oldItems, f.newItems...)
+	f.newItems = nil
+	number := 1
+	if f.digest != nil {
+		number = f.digest.Number + 1
+	}
+	f.digest = &Digest{Number: number, Title: getTitle(time.Now())}
+	return f.digest, nil
+}
+
+func (f *fakeRadarItemsStorageService) GenerateSummary(ctx context.Context, opts SummaryOptions) (*Digest, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	activity := &radarActivity{}
+	activity.addAdded(opts, f.oldItems...)
+	activity.addAdded(opts, f.newItems...)
+	for _, item := range f.doneItems {
+		if opts.includes(item.CheckedAt) {
+			activity.Done = append(activity.Done, item)
+		}
+	}
+	activity.finish()
+	return &Digest{Title: opts.Title(), Body: generateSummaryBody(activity, opts)}, nil
+}
+
+func (f *fakeRadarItemsStorageService) Shutdown(ctx context.Context) {}
@@ -0,0 +1,263 @@
+package radar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Values for SummaryOptions.Period.
+const (
+	SummaryWeekly  = "weekly"
+	SummaryMonthly = "monthly"
+)
+
+// summaryTopHosts is how many hosts the summary lists.
+const summaryTopHosts = 5
+
+// IsValidSummaryPeriod returns true if period is SummaryWeekly or SummaryMonthly.
+func IsValidSummaryPeriod(period string) bool {
+	return period == SummaryWeekly || period == SummaryMonthly
+}
+
+// IsSummaryDue returns true if now is the day to summarize the period just ended: Monday
+// for weekly summaries, and the 1st for monthly ones.
+func IsSummaryDue(period string, now time.Time) bool {
+	switch period {
+	case SummaryWeekly:
+		return now.Weekday() == time.Monday
+	case SummaryMonthly:
+		return now.Day() == 1
+	}
+	return false
+}
+
+// SummaryOptions configures a summary of the radar's activity over a week or month.
+type SummaryOptions struct {
+	// Period is SummaryWeekly or SummaryMonthly.
+	Period string
+	// Start and End bound the period summarized. Start is inclusive, End is exclusive.
+	Start, End time.Time
+	// Mention is who to greet in the summary, e.g. "@parkr".
+	Mention string
+}
+
+// NewSummaryOptions returns options for summarizing the last full period before now: the
+// week starting on a Monday, or the calendar month.
+func NewSummaryOptions(period string, now time.Time) SummaryOptions {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	opts := SummaryOptions{Period: period}
+	if period == SummaryMonthly {
+		opts.End = today.AddDate(0, 0, 1-today.Day())
+		opts.Start = opts.End.AddDate(0, -1, 0)
+	} else {
+		opts.End = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		opts.Start = opts.End.AddDate(0, 0, -7)
+	}
+	return opts
+}
+
+// Name is a short name for the period, e.g. "2026-10" or "week-of-2026-10-12".
+func (opts SummaryOptions) Name() string {
+	if opts.Period == SummaryMonthly {
+		return opts.Start.Format("2006-01")
+	}
+	return "week-of-" + opts.Start.Format("2006-01-02")
+}
+
+// Title is the human-readable title, e.g. "Radar summary for October 2026".
+func (opts SummaryOptions) Title() string {
+	if opts.Period == SummaryMonthly {
+		return "Radar summary for " + opts.Start.Format("January 2006")
+	}
+	return "Radar summary for the week of " + opts.Start.Format("2006-01-02")
+}
+
+// includes returns true if t falls within the period.
+func (opts SummaryOptions) includes(t time.Time) bool {
+	return !t.Before(opts.Start) && t.Before(opts.End)
+}
+
+// GenerateRadarSummary summarizes the radar's activity over a period using the given storage backend.
+func GenerateRadarSummary(radarItemsService RadarItemsStorageService, opts SummaryOptions) (*Digest, error) {
+	// Summaries read every digest in the period, which can take a while on GitHub.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	return radarItemsService.GenerateSummary(ctx, opts)
+}
+
+// backlogSize is the number of unchecked items in a digest when it was generated.
+type backlogSize struct {
+	Date time.Time
+	Size int
+}
+
+// radarActivity is what happened on the radar during a summary's period.
+type radarActivity struct {
+	// Added are the items added during the period, whether or not they're still on the radar.
+	Added []RadarItem
+	// Done are the items checked off during the period.
+	Done []RadarItem
+	// Expired are the items which expired during the period.
+	Expired []RadarItem
+	// Backlog is the size of each digest generated during the period.
+	Backlog []backlogSize
+}
+
+// addAdded records the items which were added during the period. Items are recorded once,
+// however many digests they appear in.
+func (a *radarActivity) addAdded(opts SummaryOptions, items ...RadarItem) {
+	for _, item := range items {
+		if opts.includes(item.AddedAt) {
+			a.Added = append(a.Added, item)
+		}
+	}
+}
+
+// finish removes duplicates and sorts everything, ready to render.
+func (a *radarActivity) finish() {
+	a.Added = uniqueRadarItems(a.Added)
+	a.Done = uniqueRadarItems(a.Done)
+	a.Expired = uniqueRadarItems(a.Expired)
+	sort.SliceStable(a.Added, func(i, j int) bool { return a.Added[i].AddedAt.Before(a.Added[j].AddedAt) })
+	sortDoneRadarItems(a.Done)
+	sort.Stable(RadarItems(a.Expired))
+	sort.SliceStable(a.Backlog, func(i, j int) bool { return a.Backlog[i].Date.Before(a.Backlog[j].Date) })
+}
+
+// uniqueRadarItems drops items whose canonical URL appeared earlier in items.
+func uniqueRadarItems(items []RadarItem) []RadarItem {
+	var unique []RadarItem
+	seen := map[string]bool{}
+	for _, item := range items {
+		canonicalURL := canonicalizeURL(item.URL)
+		if seen[canonicalURL] {
+			continue
+		}
+		seen[canonicalURL] = true
+		unique = append(unique, item)
+	}
+	return unique
+}
+
+// hostCount is the number of items from a single host.
+type hostCount struct {
+	Host  string
+	Count int
+}
+
+// topHosts returns the hosts with the most items, most first, ties broken by name.
+func topHosts(items []RadarItem, n int) []hostCount {
+	counts := map[string]int{}
+	for _, item := range items {
+		if host := radarItemGroupers[GroupByHost](item); host != "" {
+			counts[host]++
+		}
+	}
+	hosts := make([]hostCount, 0, len(counts))
+	for host, count := range counts {
+		hosts = append(hosts, hostCount{host, count})
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].Count != hosts[j].Count {
+			return hosts[i].Count > hosts[j].Count
+		}
+		return hosts[i].Host < hosts[j].Host
+	})
+	if len(hosts) > n {
+		hosts = hosts[:n]
+	}
+	return hosts
+}
+
+// pluralize returns e.g. "1 link" or "2 links".
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// generateSummaryBody renders the activity as Markdown.
+func generateSummaryBody(activity *radarActivity, opts SummaryOptions) string {
+	buf := &bytes.Buffer{}
+	lastDay := opts.End.AddDate(0, 0, -1)
+	fmt.Fprintf(buf, "Here's what happened on your radar from %s to %s", opts.Start.Format("2006-01-02"), lastDay.Format("2006-01-02"))
+	if opts.Mention != "" {
+		fmt.Fprintf(buf, ", %s", opts.Mention)
+	}
+	fmt.Fprint(buf, ":\n\n")
+
+	fmt.Fprintf(buf, "- %s added\n", pluralize(len(activity.Added), "link"))
+	fmt.Fprintf(buf, "- %s read\n", pluralize(len(activity.Done), "link"))
+	fmt.Fprintf(buf, "- %s expired\n", pluralize(len(activity.Expired), "link"))
+	if len(activity.Backlog) > 0 {
+		first, last := activity.Backlog[0], activity.Backlog[len(activity.Backlog)-1]
+		fmt.Fprintf(buf, "- Backlog went from %d to %d (%+d) over %s\n", first.Size, last.Size, last.Size-first.Size, pluralize(len(activity.Backlog), "digest"))
+	}
+
+	if hosts := topHosts(activity.Added, summaryTopHosts); len(hosts) > 0 {
+		fmt.Fprint(buf, "\n## Top hosts:\n\n")
+		for _, host := range hosts {
+			fmt.Fprintf(buf, "  * %s (%d)\n", host.Host, host.Count)
+		}
+	}
+
+	if len(activity.Backlog) > 0 {
+		fmt.Fprint(buf, "\n## Backlog:\n\n")
+		for _, size := range activity.Backlog {
+			fmt.Fprintf(buf, "  * %s: %d\n", size.Date.Format("Mon 2006-01-02"), size.Size)
+		}
+	}
+
+	for _, section := range []struct {
+		heading string
+		items   []RadarItem
+	}{
+		{"Read:", activity.Done},
+		{"Expired:", activity.Expired},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(buf, "\n## %s\n\n", section.heading)
+		for _, item := range section.items {
+			fmt.Fprintf(buf, "  * %s\n", item.GetMarkdown())
+		}
+	}
+	return buf.String()
+}
+
+// expiredSectionRegexp matches the heading of the "Expired:" section of a digest.
+var expiredSectionRegexp = regexp.MustCompile(`^#+\s*Expired:\s*$`)
+
+// listItemRegexp matches a Markdown list item.
+var listItemRegexp = regexp.MustCompile(`^\s*[-*+]\s+(.+)$`)
+
+// extractExpiredLinksFromMarkdown returns the items listed in a digest's "Expired:" section.
+func extractExpiredLinksFromMarkdown(body string) []RadarItem {
+	var items []RadarItem
+	inExpired := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "#") {
+			inExpired = expiredSectionRegexp.MatchString(line)
+			continue
+		}
+		if !inExpired {
+			continue
+		}
+		matches := listItemRegexp.FindStringSubmatch(line)
+		if matches == nil || linkedTodoLineRegexp.MatchString(line) || strings.HasPrefix(strings.ToLower(matches[1]), "[x] ") {
+			continue
+		}
+		if item := parseLinkedTodo(matches[1]); item.URL != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
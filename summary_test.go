@@ -0,0 +1,94 @@
+package radar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSummaryOptions(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 3, 0, 0, 0, time.UTC) // A Sunday.
+
+	weekly := NewSummaryOptions(SummaryWeekly, now)
+	assert.Equal(t, time.Date(2026, time.October, 5, 0, 0, 0, 0, time.UTC), weekly.Start)
+	assert.Equal(t, time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC), weekly.End)
+	assert.Equal(t, "week-of-2026-10-05", weekly.Name())
+	assert.Equal(t, "Radar summary for the week of 2026-10-05", weekly.Title())
+
+	monthly := NewSummaryOptions(SummaryMonthly, now)
+	assert.Equal(t, time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC), monthly.Start)
+	assert.Equal(t, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), monthly.End)
+	assert.Equal(t, "2026-09", monthly.Name())
+	assert.Equal(t, "Radar summary for September 2026", monthly.Title())
+
+	monday := time.Date(2026, time.October, 19, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC), NewSummaryOptions(SummaryWeekly, monday).Start)
+}
+
+func TestIsSummaryDue(t *testing.T) {
+	monday := time.Date(2026, time.October, 19, 3, 0, 0, 0, time.UTC)
+	first := time.Date(2026, time.November, 1, 3, 0, 0, 0, time.UTC)
+
+	assert.True(t, IsSummaryDue(SummaryWeekly, monday))
+	assert.False(t, IsSummaryDue(SummaryWeekly, first))
+	assert.True(t, IsSummaryDue(SummaryMonthly, first))
+	assert.False(t, IsSummaryDue(SummaryMonthly, monday))
+	assert.False(t, IsSummaryDue("", monday))
+}
+
+func Test_extractExpiredLinksFromMarkdown(t *testing.T) {
+	body, err := generateBody(&tmplData{
+		Mention:      "@parkr",
+		NewLinks:     []RadarItem{{URL: "https://byparker.com", Title: "By Parker"}},
+		ExpiredLinks: []RadarItem{{URL: "https://xkcd.com", Title: "xkcd", Tags: []string{"comics"}, AddedAt: time.Now().Add(-100 * 24 * time.Hour)}},
+	})
+	assert.NoError(t, err)
+
+	items := extractExpiredLinksFromMarkdown(body)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "xkcd", items[0].Title)
+		assert.Equal(t, "https://xkcd.com", items[0].URL)
+		assert.Equal(t, []string{"comics"}, items[0].Tags)
+	}
+}
+
+func Test_generateSummaryBody(t *testing.T) {
+	opts := SummaryOptions{
+		Period:  SummaryWeekly,
+		Start:   time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Mention: "@parkr",
+	}
+	activity := &radarActivity{
+		Added: []RadarItem{
+			{URL: "https://jvns.ca/blog", Title: "Julia Evans"},
+			{URL: "https://www.jvns.ca/zines", Title: "Wizard Zines"},
+			{URL: "https://byparker.com", Title: "By Parker"},
+		},
+		Done:    []RadarItem{{URL: "https://jvns.ca/blog", Title: "Julia Evans"}},
+		Backlog: []backlogSize{{Date: opts.Start, Size: 10}, {Date: opts.Start.AddDate(0, 0, 1), Size: 12}},
+	}
+
+	body := generateSummaryBody(activity, opts)
+	assert.Contains(t, body, "from 2026-10-12 to 2026-10-18, @parkr:")
+	assert.Contains(t, body, "- 3 links added\n- 1 link read\n- 0 links expired\n- Backlog went from 10 to 12 (+2) over 2 digests\n")
+	assert.Contains(t, body, "## Top hosts:\n\n  * jvns.ca (2)\n  * byparker.com (1)\n")
+	assert.Contains(t, body, "  * Mon 2026-10-12: 10\n")
+	assert.Contains(t, body, "## Read:\n\n  * [Julia Evans](https://jvns.ca/blog)\n")
+	assert.NotContains(t, body, "## Expired:")
+}
+
+func TestGenerateRadarSummary_FakeStorage(t *testing.T) {
+	opts := NewSummaryOptions(SummaryWeekly, time.Now())
+	storage := &fakeRadarItemsStorageService{
+		newItems:  []RadarItem{{URL: "https://jvns.ca", Title: "Julia Evans", AddedAt: opts.Start.Add(time.Hour)}},
+		oldItems:  []RadarItem{{URL: "https://byparker.com", Title: "By Parker", AddedAt: opts.Start.Add(-time.Hour)}},
+		doneItems: []RadarItem{{URL: "https://xkcd.com", Title: "xkcd", CheckedAt: opts.Start.Add(time.Hour)}},
+	}
+
+	summary, err := GenerateRadarSummary(storage, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, opts.Title(), summary.Title)
+	assert.Contains(t, summary.Body, "- 1 link added\n- 1 link read\n")
+}